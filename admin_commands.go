@@ -0,0 +1,228 @@
+package goapp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Admin command handler. Returned text is printed by `ctl` command.
+type AdminCommandFunc func(ctx context.Context, args []string) (string, error)
+
+type adminCommand struct {
+	name        string
+	description string
+	handler     AdminCommandFunc
+}
+
+// Registers admin command for running instance. It is called with `ctl <name> [args...]` CLI
+// command through control socket or admin listener. Should be called before Run().
+func (app *AppBase) AddAdminCommand(name, description string, handler AdminCommandFunc) *AppBase {
+	if name == "" || strings.ContainsAny(name, " /") {
+		log.Panicf("invalid admin command name '%s'", name)
+	}
+
+	if slices.ContainsFunc(app.adminCommands, func(ac *adminCommand) bool { return ac.name == name }) {
+		log.Panicf("admin command '%s' already registered", name)
+	}
+
+	app.adminCommands = append(app.adminCommands, &adminCommand{name: name, description: description, handler: handler})
+
+	return app //for method chaining
+}
+
+func (app *AppBase) adminCommand(name string) *adminCommand {
+	for _, ac := range app.adminCommands {
+		if ac.name == name {
+			return ac
+		}
+	}
+
+	return nil
+}
+
+// Tells if `ctl drain` was called: readiness endpoint fails so load balancers stop sending requests.
+func (app *AppBase) IsDraining() bool {
+	return app.draining.Load()
+}
+
+// Built-in commands
+func (app *AppBase) addBuiltInAdminCommands() {
+	app.AddAdminCommand("status", "Shows running instance status", app.statusAdminCommand)
+	app.AddAdminCommand("reload", "Reopens log files, reloads templates and calls application ReloadF", app.reloadAdminCommand)
+	app.AddAdminCommand("drain", "Fails readiness checks to drain traffic before shutdown ('drain off' to undo)", app.drainAdminCommand)
+	app.AddAdminCommand("jobs", "Shows worker pools jobs", app.jobsAdminCommand)
+	app.AddAdminCommand("loglevel", "Shows or sets log level: debug, info, warn or error", app.logLevelAdminCommand)
+}
+
+func (app *AppBase) statusAdminCommand(ctx context.Context, args []string) (string, error) {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%s %s (pid %d)\n", app.AppName, app.Version, os.Getpid())
+	fmt.Fprintf(&sb, "Uptime: %s\n", time.Since(processStartTime).Round(time.Second))
+	fmt.Fprintf(&sb, "Listen: %s\n", strings.Join(app.listenAddresses(), ", "))
+	fmt.Fprintf(&sb, "Log level: %s\n", app.logLevel.Level())
+	fmt.Fprintf(&sb, "Goroutines: %d\n", runtime.NumGoroutine())
+
+	if state := app.Maintenance(); state != nil {
+		fmt.Fprintf(&sb, "Maintenance: on since %s\n", state.Since.Format(time.DateTime))
+	} else {
+		sb.WriteString("Maintenance: off\n")
+	}
+
+	fmt.Fprintf(&sb, "Draining: %t\n", app.IsDraining())
+
+	names := make([]string, 0, len(app.startedComponents))
+	for _, c := range app.startedComponents {
+		names = append(names, c.Name())
+	}
+
+	fmt.Fprintf(&sb, "Components: %s\n", strings.Join(names, ", "))
+
+	return sb.String(), nil
+}
+
+func (app *AppBase) reloadAdminCommand(ctx context.Context, args []string) (string, error) {
+	if err := app.reopenLogFiles(); err != nil {
+		return "", err
+	}
+
+	if app.templates != nil {
+		if err := app.loadTemplates(); err != nil {
+			return "", err
+		}
+	}
+
+	if app.ReloadF != nil {
+		if err := app.ReloadF(); err != nil {
+			return "", err
+		}
+	}
+
+	app.logger.Info("Reloaded by admin command", "component", LogComponentApp)
+
+	return "Reloaded\n", nil
+}
+
+func (app *AppBase) drainAdminCommand(ctx context.Context, args []string) (string, error) {
+	if len(args) > 0 && args[0] == "off" {
+		app.draining.Store(false)
+		app.logger.Info("Draining stopped", "component", LogComponentApp)
+
+		return "Draining stopped, readiness checks are back\n", nil
+	}
+
+	app.draining.Store(true)
+	app.logger.Info("Draining started", "component", LogComponentApp)
+
+	return "Draining started, readiness checks fail now\n", nil
+}
+
+func (app *AppBase) jobsAdminCommand(ctx context.Context, args []string) (string, error) {
+	app.workerPoolsMutex.Lock()
+	defer app.workerPoolsMutex.Unlock()
+
+	if len(app.workerPools) == 0 {
+		return "No worker pools\n", nil
+	}
+
+	var sb strings.Builder
+
+	for _, wp := range app.workerPools {
+		s := wp.Stats()
+		fmt.Fprintf(&sb, "Pool %s: workers=%d waiting=%d running=%d completed=%d\n", wp.name, s.Workers, s.Waiting, s.Running, s.Completed)
+	}
+
+	return sb.String(), nil
+}
+
+func (app *AppBase) logLevelAdminCommand(ctx context.Context, args []string) (string, error) {
+	if len(args) > 0 {
+		level, err := ParseLogLevel(args[0])
+		if err != nil {
+			return "", err
+		}
+
+		app.SetLogLevel(level)
+		app.logger.Info("Log level changed by admin command", "component", LogComponentApp, "level", level)
+	}
+
+	return fmt.Sprintf("Log level: %s\n", app.logLevel.Level()), nil
+}
+
+// Admin command reply from running instance
+type adminCommandReply struct {
+	Status  string `json:"status"`
+	Output  string `json:"output,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Calls admin command of running instance through control socket or admin listener.
+func (app *AppBase) callAdminCommand(name string, args []string) (string, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	baseUrl := ""
+
+	switch {
+	case app.baseSettings.ControlSocket != "":
+		socket := app.baseSettings.ControlSocket
+		client.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+			},
+		}
+		baseUrl = "http://control"
+	case app.baseSettings.AdminListen != "":
+		host, port, err := net.SplitHostPort(app.baseSettings.AdminListen)
+		if err != nil {
+			return "", fmt.Errorf("invalid admin_listen: %w", err)
+		}
+
+		if host == "" || host == "0.0.0.0" || host == "::" {
+			host = "localhost"
+		}
+
+		baseUrl = "http://" + net.JoinHostPort(host, port)
+	default:
+		return "", errors.New("set control_socket or admin_listen to use admin commands")
+	}
+
+	body, _ := json.Marshal(map[string]any{"args": args})
+
+	request, err := http.NewRequest(http.MethodPost, baseUrl+adminCommandsPath+"/"+name, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	if app.baseSettings.AdminToken != "" {
+		request.Header.Set("Authorization", "Bearer "+app.baseSettings.AdminToken)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return "", fmt.Errorf("can not connect to running instance: %w", err)
+	}
+	defer response.Body.Close()
+
+	var reply adminCommandReply
+	if err := json.NewDecoder(response.Body).Decode(&reply); err != nil {
+		return "", fmt.Errorf("unexpected reply from running instance (%s): %w", response.Status, err)
+	}
+
+	if reply.Status != "ok" {
+		return "", errors.New(reply.Message)
+	}
+
+	return reply.Output, nil
+}
@@ -0,0 +1,117 @@
+package goapp
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAdminCommands(t *testing.T) {
+	// short path, unix socket path length is limited
+	dir, err := os.MkdirTemp("", "goapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	app := NewAppBase(&testAppSettings{})
+	app.baseSettings.ControlSocket = filepath.Join(dir, "ctl.sock")
+	app.baseSettings.AdminToken = "secret" // not required for socket
+
+	app.AddAdminCommand("echo", "Prints arguments", func(ctx context.Context, args []string) (string, error) {
+		return strings.Join(args, " "), nil
+	})
+
+	app.buildWebRouter()
+
+	srv, l, err := app.openControlSocket()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go srv.Serve(l)
+	defer srv.Close()
+
+	if info, err := os.Stat(app.baseSettings.ControlSocket); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("unexpected socket permissions: %v %v", info, err)
+	}
+
+	if output, err := app.callAdminCommand("echo", []string{"hello", "world"}); err != nil || output != "hello world" {
+		t.Errorf("unexpected echo output: %q %v", output, err)
+	}
+
+	if output, err := app.callAdminCommand("status", nil); err != nil || !strings.Contains(output, "Draining: false") {
+		t.Errorf("unexpected status output: %q %v", output, err)
+	}
+
+	if _, err := app.callAdminCommand("loglevel", []string{"debug"}); err != nil || app.logLevel.Level() != slog.LevelDebug {
+		t.Errorf("log level is not changed: %s %v", app.logLevel.Level(), err)
+	}
+
+	if _, err := app.callAdminCommand("loglevel", []string{"loud"}); err == nil {
+		t.Error("invalid log level should fail")
+	}
+
+	if _, err := app.callAdminCommand("no-such-command", nil); err == nil || !strings.Contains(err.Error(), "unknown admin command") {
+		t.Errorf("unknown command should fail: %v", err)
+	}
+
+	// drain fails readiness
+	if _, err := app.callAdminCommand("drain", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	app.webRouter.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	if w.Code != http.StatusServiceUnavailable || !strings.Contains(w.Body.String(), "draining") {
+		t.Errorf("readiness should fail while draining: %d %s", w.Code, w.Body.String())
+	}
+
+	app.callAdminCommand("drain", []string{"off"})
+
+	if app.IsDraining() {
+		t.Error("draining should be stopped")
+	}
+}
+
+func TestAdminCommandsToken(t *testing.T) {
+	app := NewAppBase(&testAppSettings{})
+	app.baseSettings.AdminListen = "127.0.0.1:0"
+	app.baseSettings.AdminToken = "secret"
+	app.buildWebRouter()
+
+	for token, expected := range map[string]int{"": http.StatusUnauthorized, "secret": http.StatusOK} {
+		request := httptest.NewRequest(http.MethodPost, adminCommandsPath+"/status", nil)
+		if token != "" {
+			request.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		w := httptest.NewRecorder()
+		app.AdminHandler().ServeHTTP(w, request)
+
+		if w.Code != expected {
+			t.Errorf("token '%s': expected %d, got %d", token, expected, w.Code)
+		}
+	}
+
+	// without admin_token commands are run on loopback admin listener only
+	app.baseSettings.AdminToken = ""
+
+	for address, expected := range map[string]int{"127.0.0.1:0": http.StatusOK, ":15116": http.StatusForbidden, "192.168.1.10:15116": http.StatusForbidden} {
+		app.baseSettings.AdminListen = address
+		app.buildWebRouter()
+
+		w := httptest.NewRecorder()
+		app.AdminHandler().ServeHTTP(w, httptest.NewRequest(http.MethodPost, adminCommandsPath+"/status", nil))
+
+		if w.Code != expected {
+			t.Errorf("admin_listen %s without token: expected %d, got %d", address, expected, w.Code)
+		}
+	}
+}
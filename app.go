@@ -4,11 +4,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
 	"reflect"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -44,30 +52,97 @@ type AppBase struct {
 
 	serviceAutostart bool
 
-	rootCmd *cobra.Command
+	//own database of application. Set to DbSchema to use package level helpers (LoadO, LoadOL etc).
+	Database *Database
+
+	//logging
+	logger            *slog.Logger
+	logLevel          slog.LevelVar
+	logFile           *LogFile
+	accessLogFile     *LogFile
+	accessLog         io.Writer // access log output
+	accessLogToAppLog bool      // access log records go to application log
+	logReopenOnce     sync.Once
+
+	rootCmd     *cobra.Command
+	initialized bool // internalInit() done
 
 	//contexts and timeout settings
-	BaseContext     context.Context
-	ShutdownTimeout time.Duration
+	BaseContext          context.Context    // root context, `run` command cancels it when shutdown starts
+	cancelBaseContext    context.CancelFunc // set by `run` command
+	ShutdownTimeout      time.Duration
+	ComponentStopTimeout time.Duration // timeout for each component Stop() call
+
+	//components with managed lifecycle
+	components        []Component
+	startedComponents []Component
+
+	//pluggable modules, see UseModule()
+	modules []AppModule
 
 	//web router
 	webRouter           *gin.Engine
-	WebRouterLogQueries bool                // true = extended query logging (--query-log option of `run`)
+	trustedProxyNets    []*net.IPNet
+	WebRouterLogQueries bool                // true = access log to application log (deprecated --query-log option of `run`), see access_log setting
 	BuildWebRouterF     func(r *gin.Engine) // function to build web router for `run` command
 
 	//web api
 	WebApiPathPrefix  string // usually "/api". Leave empty to disable web API at all.
-	WebApiEnableGet   bool   // Serve both POST and GET methods. Default 'false' = POST-requests only.
-	webApiHandlerList map[string]ApiRequestHandler
+	WebApiEnableGet   bool   // Serve both POST and GET methods. Default 'false' = POST-requests only. GET requests need CSRF token too.
+	webApiHandlerList map[string]*apiHandlerEntry
+	webApiRateLimiter *rateLimiter // global API rate limiter from settings
+
+	//receives panics and 5xx errors, see ErrorReply()
+	ErrorReporter ErrorReporter
+
+	//returns current user ID (empty = anonymous) for access log and RateLimitByUser limits
+	CurrentUserIdF func(c *gin.Context) string
+
+	//health endpoints, see AddHealthCheck()
+	HealthPath         string // liveness endpoint, "/healthz" by default. Empty = disabled.
+	ReadinessPath      string // readiness endpoint, "/readyz" by default. Empty = disabled.
+	healthChecks       []*healthCheck
+	workerPools        []*WorkerPool // running pools started with StartWorkerPool()
+	workerPoolsCounter int           // for unnamed pools names
+	workerPoolsMutex   sync.Mutex
+
+	//metrics, see Metrics()
+	MetricsPath string // metrics endpoint, "/metrics" by default. Empty = disabled.
+	metrics     *MetricsRegistry
+	httpMetrics *httpMetrics
+
+	//admin listener router (admin_listen setting)
+	adminRouter *gin.Engine
+
+	//admin commands for `ctl` command, see AddAdminCommand()
+	adminCommands []*adminCommand
+	draining      atomic.Bool
+
+	//maintenance mode, see SetMaintenance()
+	MaintenanceReplyF    func(c *gin.Context, state *MaintenanceState) // custom 503 reply in maintenance mode
+	IsAdminF             func(c *gin.Context) bool                     // tells if current session is admin one (served in maintenance mode)
+	maintenance          maintenanceCache
+	maintenanceAllowNets []*net.IPNet
+
+	//static files, see ServeStatic()
+	staticMounts []*staticMount
+
+	//html templates, see UseTemplates()
+	templates               *templateEngine
+	templateRequestFuncList map[string]func(c *gin.Context) any
+
+	//Content-Security-Policy, see Csp()
+	cspPolicy *CspPolicy
 
 	//callbacks (aka event handlers)
 	PreCmdF  func(cmd *cobra.Command) error // called before any subcommand. Stops executions if error returned.
 	PostCmdF func(cmd *cobra.Command) error // called after any subcommand. Stops executions if error returned.
 
-	PreRunF    func() error // called before starting `run` command. Stops executions if error returned.
-	PostRunF   func() error // called after finishing `run` command. Stops executions if error returned.
+	PreRunF    func() error // called before starting `run` command (before components are started). Stops executions if error returned.
+	PostRunF   func() error // called after finishing `run` command (after components are stopped). Stops executions if error returned.
 	InitF      func() error // Additional code for `init` subcommand. Stops executions if error returned.
 	PrintInfoF func()       // Prints additional information when `info` subcommand called.
+	ReloadF    func() error // called by `ctl reload` command of running instance
 
 	BuildCustomCommandsF func(rootCmd *cobra.Command) // Set this to add any custom subcommands
 }
@@ -81,7 +156,20 @@ func NewAppBase(defaultSettings interface{}) *AppBase {
 	app.Global = make(map[string]interface{})
 
 	//web api routes list
-	app.webApiHandlerList = make(map[string]ApiRequestHandler)
+	app.webApiHandlerList = make(map[string]*apiHandlerEntry)
+
+	//health endpoints
+	app.HealthPath = "/healthz"
+	app.ReadinessPath = "/readyz"
+	app.addBuiltInHealthChecks()
+
+	//metrics
+	app.MetricsPath = "/metrics"
+	app.metrics = newMetricsRegistry()
+	app.registerBuiltInMetrics()
+
+	//admin commands
+	app.addBuiltInAdminCommands()
 
 	//default settings values
 	app.AppSettingsFilename = ".settings.yml"
@@ -101,14 +189,39 @@ func NewAppBase(defaultSettings interface{}) *AppBase {
 	app.baseSettings = v.FieldByName(base_settings_type.Name()).Addr().Interface().(*AppSettingsBase)
 
 	app.baseSettings.checkDefaultValues(&AppSettingsBase{
-		WebserverHostname:   "localhost",
-		WebserverPort:       15115,
-		ServiceName:         app.ExecutableName,
-		ServiceUser:         "www-data",
-		ServiceGroup:        "www-data",
-		InitialRootPassword: mttools.RandomString(20),
+		WebserverHostname:           "localhost",
+		WebserverPort:               15115,
+		WebserverSocketMode:         "0660",
+		WebserverTlsMinVersion:      "1.2",
+		WebserverForwardedHeaders:   []string{"X-Forwarded-For", "X-Real-IP", "Forwarded"},
+		WebserverCompressionMinSize: 1024,
+		WebserverCompressionTypes: []string{
+			"text/html", "text/css", "text/plain", "text/javascript", "text/xml", "text/csv",
+			"application/javascript", "application/json", "application/xml", "application/wasm", "image/svg+xml",
+		},
+		WebApiCorsHeaders:         []string{"Content-Type", CsrfHeaderName},
+		WebApiCorsMaxAge:          600,
+		SecurityHstsMaxAge:        365 * 24 * 3600,
+		SecurityReferrerPolicy:    "strict-origin-when-cross-origin",
+		SecurityPermissionsPolicy: "camera=(), microphone=(), geolocation=(), payment=()",
+		ServiceName:               app.ExecutableName,
+		ServiceUser:               "www-data",
+		ServiceGroup:              "www-data",
+		LogLevel:                  "info",
+		LogFormat:                 "text",
+		LogOutput:                 "stderr",
+		LogFileMaxSize:            100,
+		LogFileMaxBackups:         10,
+		AccessLogFormat:           "combined",
+		AccessLogSampleRate:       1,
+		InitialRootPassword:       mttools.RandomString(20),
 	})
 
+	//default logger until settings are loaded
+	app.setLogger(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: &app.logLevel})))
+
+	app.Database = NewDatabase(dbFileName)
+
 	//global application base context
 	app.BaseContext = context.Background()
 
@@ -124,6 +237,7 @@ func NewAppBase(defaultSettings interface{}) *AppBase {
 	app.AppName = "UNSET_AppName"
 
 	app.ShutdownTimeout = 10 * time.Second
+	app.ComponentStopTimeout = 10 * time.Second
 
 	//build root cobra cmd
 	app.buildRootCmd()
@@ -140,7 +254,79 @@ func (app *AppBase) Run() {
 	}
 }
 
+// Prepares application to serve web requests without cobra commands: loads settings from
+// AppSettingsFilename, builds web router and starts components. Used by tests (see goapptest
+// package) or to embed application into another server. Call Shutdown() when done.
+func (app *AppBase) Startup() error {
+	app.internalInit()
+
+	if err := app.loadSettings(); err != nil {
+		return err
+	}
+
+	if err := app.initLogger(); err != nil {
+		return err
+	}
+
+	return app.startup()
+}
+
+// Stops application started with Startup() or `run` command: cancels BaseContext, stops
+// components, calls PostRunF and closes log files.
+func (app *AppBase) Shutdown() error {
+	// make sure root context is canceled in any case
+	app.cancelBase()
+
+	// web server is drained already, components can be stopped now
+	err := app.stopComponents()
+
+	if app.PostRunF != nil {
+		err = errors.Join(err, app.PostRunF())
+	}
+
+	app.logger.Info("Shutdown complete")
+
+	app.closeLogFiles()
+
+	return err
+}
+
+// Returns web router http.Handler. Available after startup only.
+func (app *AppBase) Handler() http.Handler {
+	return app.stripPathPrefix(app.webRouter.Handler())
+}
+
+// Startup procedures for `run` command and Startup(). Settings are loaded already.
+func (app *AppBase) startup() error {
+	app.logger.Info(app.AppName+" starting", "version", app.Version)
+
+	app.initBaseContext()
+	app.buildWebRouter()
+
+	if err := app.loadTemplates(); err != nil {
+		return err
+	}
+
+	if app.templates != nil && app.templates.options.DevDir == "" && app.IsDevMode() {
+		app.componentLogger(LogComponentWeb).Warn("DEV mode: templates are not reloaded, TemplateOptions.DevDir is not set")
+	}
+
+	if app.PreRunF != nil {
+		if err := app.PreRunF(); err != nil {
+			return err
+		}
+	}
+
+	return app.startComponents(app.BaseContext)
+}
+
 func (app *AppBase) internalInit() {
+	if app.initialized {
+		return
+	}
+
+	app.initialized = true
+
 	//post-setup root cmd
 	app.rootCmd.Use = app.ExecutableName
 	app.rootCmd.Long = app.AppName
@@ -164,7 +350,7 @@ func (app *AppBase) internalInit() {
 
 		//should start from slash
 		if !strings.HasPrefix(app.WebApiPathPrefix, "/") {
-			app.WebApiPathPrefix += "/"
+			app.WebApiPathPrefix = "/" + app.WebApiPathPrefix
 		}
 	}
 
@@ -176,11 +362,15 @@ func (app *AppBase) internalInit() {
 		app.buildInitCmd(),
 		app.buildInfoCmd(),
 		app.buildRunCmd(),
+		app.buildMaintenanceCmd(),
+		app.buildCtlCmd(),
 	)
 
 	if app.BuildCustomCommandsF != nil {
 		app.BuildCustomCommandsF(app.rootCmd)
 	}
+
+	app.addModulesCommands()
 }
 
 func (app *AppBase) loadSettings() error {
@@ -188,6 +378,10 @@ func (app *AppBase) loadSettings() error {
 		if err := mttools.LoadYamlSettingFromFile(app.AppSettingsFilename, app.AppSettings); err != nil {
 			return err
 		}
+
+		if err := app.loadModulesSettings(); err != nil {
+			return err
+		}
 	} else {
 		return fmt.Errorf("File not found: %s", app.AppSettingsFilename)
 	}
@@ -212,7 +406,12 @@ func (app *AppBase) loadSettings() error {
 	} else {
 		// or use pre-defined values in DEV
 		if app.baseSettings.BaseUrl == "" {
-			app.baseSettings.BaseUrl = "http://" + app.baseSettings.WebserverHostname +
+			scheme := "http://"
+			if app.baseSettings.WebserverTls {
+				scheme = "https://"
+			}
+
+			app.baseSettings.BaseUrl = scheme + app.baseSettings.WebserverHostname +
 				":" + strconv.Itoa(int(app.baseSettings.WebserverPort))
 		}
 
@@ -221,23 +420,137 @@ func (app *AppBase) loadSettings() error {
 		}
 	}
 
+	if _, err := parseIpNets(app.baseSettings.WebserverTrustedProxies); err != nil {
+		return fmt.Errorf("invalid webserver_trusted_proxies: %w", err)
+	}
+
+	if _, err := parseIpNets(app.baseSettings.MaintenanceAllowIps); err != nil {
+		return fmt.Errorf("invalid maintenance_allow_ips: %w", err)
+	}
+
 	return nil
 }
 
-func (app *AppBase) saveSettings(comment string) error {
-	return mttools.SaveYamlSettingToFile(app.AppSettingsFilename, comment, app.AppSettings)
+// Writes application settings and modules settings sections to AppSettingsFilename.
+func (app *AppBase) SaveSettings(comment string) error {
+	if err := mttools.SaveYamlSettingToFile(app.AppSettingsFilename, comment, app.AppSettings); err != nil {
+		return err
+	}
+
+	if !app.hasModulesSettings() {
+		return nil
+	}
+
+	//append modules sections
+	modulesYaml, err := app.modulesSettingsYaml()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(app.AppSettingsFilename, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append([]byte("\n"), modulesYaml...))
+
+	return err
 }
 
 func (app *AppBase) printSettings() {
 	mttools.PrintYamlSettings(app.AppSettings)
+
+	if app.hasModulesSettings() {
+		if modulesYaml, err := app.modulesSettingsYaml(); err == nil {
+			fmt.Println(string(modulesYaml))
+		} else {
+			app.logger.Error("Modules settings printing failed", "error", err)
+		}
+	}
 }
 
-func (app *AppBase) ApiHandler(path string, handler ApiRequestHandler) *AppBase {
-	app.webApiHandlerList[path] = handler
+// Registers web API handler for path (without WebApiPathPrefix). Options like ApiCsrfExempt() change
+// handler behaviour.
+func (app *AppBase) ApiHandler(path string, handler ApiRequestHandler, options ...ApiHandlerOption) *AppBase {
+	entry := &apiHandlerEntry{handler: handler}
+
+	for _, option := range options {
+		option(entry)
+	}
+
+	app.webApiHandlerList[path] = entry
 
 	return app //for method chaining
 }
 
+// Returns channel that is closed when application shutdown starts. Long-running user goroutines
+// should exit when it is closed.
+func (app *AppBase) Done() <-chan struct{} {
+	return app.BaseContext.Done()
+}
+
+// Starts worker pool bound to application base context. Pool is stopped when shutdown starts.
+// Pool is named by its number ("1", "2" etc) in metrics and `ctl jobs` output.
+func (app *AppBase) StartWorkerPool(workersCount int) *WorkerPool {
+	app.workerPoolsMutex.Lock()
+	app.workerPoolsCounter++
+	name := strconv.Itoa(app.workerPoolsCounter)
+	app.workerPoolsMutex.Unlock()
+
+	return app.StartNamedWorkerPool(name, workersCount)
+}
+
+// Same as StartWorkerPool() with pool name for metrics "pool" label, health check and `ctl jobs`
+// output. Names of running pools should be unique.
+func (app *AppBase) StartNamedWorkerPool(name string, workersCount int) *WorkerPool {
+	app.workerPoolsMutex.Lock()
+	defer app.workerPoolsMutex.Unlock()
+
+	if slices.ContainsFunc(app.workerPools, func(wp *WorkerPool) bool { return wp.name == name }) {
+		log.Panicf("worker pool '%s' is running already", name)
+	}
+
+	wp := startWorkerPool(app.BaseContext, workersCount, app.logger)
+	wp.name = name
+
+	//stopped pools are forgotten by health check and metrics: on Stop() and when pool stops
+	//because BaseContext is canceled
+	remove := func() {
+		app.workerPoolsMutex.Lock()
+		app.workerPools = slices.DeleteFunc(app.workerPools, func(p *WorkerPool) bool { return p == wp })
+		app.workerPoolsMutex.Unlock()
+	}
+
+	stopAfterFunc := context.AfterFunc(app.BaseContext, remove)
+
+	wp.onStop = func() {
+		stopAfterFunc()
+		remove()
+	}
+
+	app.workerPools = append(app.workerPools, wp)
+
+	return wp
+}
+
+// Derives cancellable root context from BaseContext. Called by `run` command before any
+// startup procedures.
+func (app *AppBase) initBaseContext() {
+	app.BaseContext, app.cancelBaseContext = context.WithCancel(app.BaseContext)
+
+	app.Database.SetContext(app.BaseContext)
+	app.Database.SetLogger(app.logger)
+}
+
+// Cancels root context to let handlers, worker pools, database queries and user goroutines know
+// shutdown has begun.
+func (app *AppBase) cancelBase() {
+	if app.cancelBaseContext != nil {
+		app.cancelBaseContext()
+	}
+}
+
 func (app *AppBase) IsDevMode() bool {
 	return app.Version == DEV_MODE_LABEL // && false //uncomment to debug production mode
 }
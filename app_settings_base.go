@@ -3,16 +3,81 @@ package goapp
 type AppSettingsBase struct {
 	Production bool `yaml:"production" yaml_comment:"Production mode"`
 
-	BaseUrl string `yaml:"base_url" yaml_comment:"Base external site URL (with protocol and port, no trailing slash)"`
+	BaseUrl string `yaml:"base_url" yaml_comment:"Base external site URL (with protocol and port, no trailing slash). Path (like https://host/tools/myapp) is used as prefix for all routes and cookies."`
+
+	WebserverHostname    string   `yaml:"webserver_hostname" yaml_comment:"Webserver hostname"`
+	WebserverPort        uint16   `yaml:"webserver_port" yaml_comment:"Webserver port number"`
+	WebserverListen      []string `yaml:"webserver_listen" yaml_comment:"Listeners list: tcp://host:port or unix:///path/to.sock. Empty = webserver_hostname:webserver_port."`
+	WebserverSocketMode  string   `yaml:"webserver_socket_mode" yaml_comment:"Unix socket file permissions (octal)"`
+	WebserverSocketOwner string   `yaml:"webserver_socket_owner" yaml_comment:"Unix socket file owner as 'user:group' or 'user'. Empty = do not change."`
+
+	WebserverTrustedProxies   []string `yaml:"webserver_trusted_proxies" yaml_comment:"Reverse proxies (IP addresses or CIDRs) to trust forwarded headers from. Empty = none."`
+	WebserverForwardedHeaders []string `yaml:"webserver_forwarded_headers" yaml_comment:"Headers with client IP set by trusted proxies: X-Forwarded-For, X-Real-IP, Forwarded. X-Forwarded-Host and X-Forwarded-Proto are applied too."`
 
-	WebserverHostname     string `yaml:"webserver_hostname" yaml_comment:"Webserver hostname"`
-	WebserverPort         uint16 `yaml:"webserver_port" yaml_comment:"Webserver port number"`
 	WebserverCookieSecret string `yaml:"webserver_cookie_secret" yaml_comment:"Secret string to encrypt cookies. Required in Production mode."`
 
+	WebserverTls                 bool     `yaml:"webserver_tls" yaml_comment:"Serve HTTPS. Self-signed certificate is generated in DEV mode if no certificate files set."`
+	WebserverTlsCertFile         string   `yaml:"webserver_tls_cert_file" yaml_comment:"TLS certificate file (PEM). Reloaded automatically when changed."`
+	WebserverTlsKeyFile          string   `yaml:"webserver_tls_key_file" yaml_comment:"TLS private key file (PEM). Reloaded automatically when changed."`
+	WebserverTlsMinVersion       string   `yaml:"webserver_tls_min_version" yaml_comment:"Minimal TLS version: 1.0, 1.1, 1.2 or 1.3"`
+	WebserverTlsCiphers          []string `yaml:"webserver_tls_ciphers" yaml_comment:"TLS cipher suites names (like TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256). Empty = Go defaults."`
+	WebserverHttpRedirectAddress string   `yaml:"webserver_http_redirect_address" yaml_comment:"Address (like ':80') for plain HTTP listener redirecting to base_url. Empty = disabled."`
+
+	WebserverShutdownDelay int `yaml:"webserver_shutdown_delay" yaml_comment:"Seconds to keep serving requests after shutdown begins while readiness endpoint fails, so load balancers stop sending traffic. 0 = no delay."`
+
+	WebserverCompressionDisabled bool     `yaml:"webserver_compression_disabled" yaml_comment:"Do not compress responses with brotli or gzip"`
+	WebserverCompressionMinSize  int      `yaml:"webserver_compression_min_size" yaml_comment:"Do not compress responses smaller than this (bytes)"`
+	WebserverCompressionTypes    []string `yaml:"webserver_compression_types" yaml_comment:"Content types to compress"`
+
+	WebApiCsrfDisabled   bool     `yaml:"web_api_csrf_disabled" yaml_comment:"Disable CSRF protection for web API (X-CSRF-Token header and Origin checks)"`
+	WebApiTrustedOrigins []string `yaml:"web_api_trusted_origins" yaml_comment:"Additional origins (like https://app.example.com) allowed to call web API with session cookies"`
+
+	WebApiRateLimit      int `yaml:"web_api_rate_limit" yaml_comment:"Web API requests per minute allowed for single client IP. 0 = no limit."`
+	WebApiRateLimitBurst int `yaml:"web_api_rate_limit_burst" yaml_comment:"Web API requests burst size for single client IP. 0 = same as web_api_rate_limit."`
+
+	WebApiCorsOrigins     []string `yaml:"web_api_cors_origins" yaml_comment:"Origins allowed to call web API from browsers: https://app.example.com, https://*.example.com or *. Empty = CORS disabled."`
+	WebApiCorsMethods     []string `yaml:"web_api_cors_methods" yaml_comment:"Methods allowed for CORS requests. Empty = POST (and GET if enabled)."`
+	WebApiCorsHeaders     []string `yaml:"web_api_cors_headers" yaml_comment:"Request headers allowed for CORS requests"`
+	WebApiCorsCredentials bool     `yaml:"web_api_cors_credentials" yaml_comment:"Allow CORS requests with cookies (session)"`
+	WebApiCorsMaxAge      int      `yaml:"web_api_cors_max_age" yaml_comment:"How long (seconds) browsers can cache preflight replies. -1 = no caching."`
+
+	SecurityHeadersDisabled   bool   `yaml:"security_headers_disabled" yaml_comment:"Do not set security headers (HSTS, CSP, X-Frame-Options etc)"`
+	SecurityHstsMaxAge        int    `yaml:"security_hsts_max_age" yaml_comment:"Strict-Transport-Security max-age (seconds) for https base_url. -1 = no HSTS header."`
+	SecurityFrameOptions      string `yaml:"security_frame_options" yaml_comment:"X-Frame-Options header value. Empty = DENY in production, SAMEORIGIN in DEV mode."`
+	SecurityReferrerPolicy    string `yaml:"security_referrer_policy" yaml_comment:"Referrer-Policy header value"`
+	SecurityPermissionsPolicy string `yaml:"security_permissions_policy" yaml_comment:"Permissions-Policy header value"`
+
+	AdminListen  string `yaml:"admin_listen" yaml_comment:"Address (like 127.0.0.1:15116) for admin listener serving metrics, admin API, admin commands and debug endpoints. Empty = disabled."`
+	AdminToken   string `yaml:"admin_token" yaml_comment:"Bearer token for admin API, admin commands and debug endpoints on admin listener and main webserver. Empty = they are served on loopback admin listener and control socket only."`
+	MetricsToken string `yaml:"metrics_token" yaml_comment:"Bearer token for metrics endpoint on main webserver (also accepted on admin listener). Empty = metrics are served on admin listener only."`
+
+	ControlSocket string `yaml:"control_socket" yaml_comment:"Unix socket for admin commands (see 'ctl' command). Only socket file owner can use it. Empty = disabled."`
+
+	DebugEndpoints             bool `yaml:"debug_endpoints" yaml_comment:"Serve /debug/pprof/*, /debug/goroutines and /debug/runtime endpoints (see admin_listen and admin_token)"`
+	DebugEndpointsInProduction bool `yaml:"debug_endpoints_in_production" yaml_comment:"Allow debug endpoints in production mode"`
+
+	MaintenanceFile     string   `yaml:"maintenance_file" yaml_comment:"Maintenance mode flag file (see 'maintenance' command). Empty = .maintenance next to settings file."`
+	MaintenanceAllowIps []string `yaml:"maintenance_allow_ips" yaml_comment:"Client IPs or CIDRs allowed to use application in maintenance mode"`
+
 	ServiceName  string `yaml:"service_name" yaml_comment:"Service name for 'install' command"`
 	ServiceUser  string `yaml:"service_user" yaml_comment:"User for 'install' command"`
 	ServiceGroup string `yaml:"service_group" yaml_comment:"Group for 'install' command"`
 
+	LogLevel  string `yaml:"log_level" yaml_comment:"Log level: debug, info, warn or error"`
+	LogFormat string `yaml:"log_format" yaml_comment:"Log format: text or json"`
+	LogOutput string `yaml:"log_output" yaml_comment:"Log output: stderr, file or both"`
+	LogFile   string `yaml:"log_file" yaml_comment:"Log file path for 'file' and 'both' log outputs"`
+
+	LogFileMaxSize    int `yaml:"log_file_max_size" yaml_comment:"Rotate log files when they get bigger than this (megabytes). 0 = no size limit."`
+	LogFileMaxAge     int `yaml:"log_file_max_age" yaml_comment:"Rotate log files when they get older than this (hours). 0 = no age limit."`
+	LogFileMaxBackups int `yaml:"log_file_max_backups" yaml_comment:"Number of compressed rotated log files to keep. 0 = keep all."`
+
+	AccessLog             string   `yaml:"access_log" yaml_comment:"HTTP access log output: file, stdout or log (application log). Empty = no access log (or file if access_log_file is set)."`
+	AccessLogFile         string   `yaml:"access_log_file" yaml_comment:"HTTP access log file path for access_log=file (rotated with log_file_* settings)"`
+	AccessLogFormat       string   `yaml:"access_log_format" yaml_comment:"HTTP access log format: common, combined or json"`
+	AccessLogSampleRate   float64  `yaml:"access_log_sample_rate" yaml_comment:"Part of requests to log: 1 = all, 0.1 = every 10th. Server errors are always logged."`
+	AccessLogExcludePaths []string `yaml:"access_log_exclude_paths" yaml_comment:"Paths not to log (like /healthz). Trailing * matches any path with given prefix."`
+
 	InitialRootPassword string `yaml:"initial_root_password" yaml_comment:"Password to authenticate root user before users database ready. !!!DELETE THIS when you set root password in GUI."`
 }
 
@@ -25,6 +90,46 @@ func (s *AppSettingsBase) checkDefaultValues(defaults *AppSettingsBase) {
 		s.WebserverPort = defaults.WebserverPort
 	}
 
+	if s.WebserverSocketMode == "" {
+		s.WebserverSocketMode = defaults.WebserverSocketMode
+	}
+
+	if s.WebserverTlsMinVersion == "" {
+		s.WebserverTlsMinVersion = defaults.WebserverTlsMinVersion
+	}
+
+	if len(s.WebserverForwardedHeaders) == 0 {
+		s.WebserverForwardedHeaders = defaults.WebserverForwardedHeaders
+	}
+
+	if s.WebserverCompressionMinSize == 0 {
+		s.WebserverCompressionMinSize = defaults.WebserverCompressionMinSize
+	}
+
+	if len(s.WebserverCompressionTypes) == 0 {
+		s.WebserverCompressionTypes = defaults.WebserverCompressionTypes
+	}
+
+	if len(s.WebApiCorsHeaders) == 0 {
+		s.WebApiCorsHeaders = defaults.WebApiCorsHeaders
+	}
+
+	if s.WebApiCorsMaxAge == 0 {
+		s.WebApiCorsMaxAge = defaults.WebApiCorsMaxAge
+	}
+
+	if s.SecurityHstsMaxAge == 0 {
+		s.SecurityHstsMaxAge = defaults.SecurityHstsMaxAge
+	}
+
+	if s.SecurityReferrerPolicy == "" {
+		s.SecurityReferrerPolicy = defaults.SecurityReferrerPolicy
+	}
+
+	if s.SecurityPermissionsPolicy == "" {
+		s.SecurityPermissionsPolicy = defaults.SecurityPermissionsPolicy
+	}
+
 	if s.ServiceName == "" {
 		s.ServiceName = defaults.ServiceName
 	}
@@ -37,6 +142,34 @@ func (s *AppSettingsBase) checkDefaultValues(defaults *AppSettingsBase) {
 		s.ServiceGroup = defaults.ServiceGroup
 	}
 
+	if s.LogLevel == "" {
+		s.LogLevel = defaults.LogLevel
+	}
+
+	if s.LogFormat == "" {
+		s.LogFormat = defaults.LogFormat
+	}
+
+	if s.LogOutput == "" {
+		s.LogOutput = defaults.LogOutput
+	}
+
+	if s.LogFileMaxSize == 0 {
+		s.LogFileMaxSize = defaults.LogFileMaxSize
+	}
+
+	if s.LogFileMaxBackups == 0 {
+		s.LogFileMaxBackups = defaults.LogFileMaxBackups
+	}
+
+	if s.AccessLogFormat == "" {
+		s.AccessLogFormat = defaults.AccessLogFormat
+	}
+
+	if s.AccessLogSampleRate == 0 {
+		s.AccessLogSampleRate = defaults.AccessLogSampleRate
+	}
+
 	if s.InitialRootPassword == "" {
 		s.InitialRootPassword = defaults.InitialRootPassword
 	}
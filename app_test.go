@@ -1,8 +1,43 @@
 package goapp
 
-import "testing"
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
 
 func TestNothing(t *testing.T) {
 	t.Log("Nothing")
 	//t.Error("Test error")
 }
+
+func TestWebApiPathPrefix(t *testing.T) {
+	for prefix, expected := range map[string]string{
+		"/api":    "/api",
+		"api":     "/api",
+		"/myapi/": "/myapi",
+		"v1/api/": "/v1/api",
+	} {
+		app := NewAppBase(&testAppSettings{})
+		app.WebApiPathPrefix = prefix
+		app.baseSettings.WebserverCookieSecret = "test"
+		app.baseSettings.WebApiCsrfDisabled = true
+
+		app.ApiHandler("/ping", func(r *ApiRequest) error { return nil })
+		app.internalInit()
+
+		if app.WebApiPathPrefix != expected {
+			t.Errorf("prefix %q: expected %q, got %q", prefix, expected, app.WebApiPathPrefix)
+		}
+
+		app.buildWebRouter()
+
+		// API routes should use configured prefix, not hardcoded "/api"
+		w := httptest.NewRecorder()
+		app.webRouter.ServeHTTP(w, httptest.NewRequest(http.MethodPost, expected+"/ping", nil))
+
+		if w.Code != http.StatusOK {
+			t.Errorf("prefix %q: expected 200 for %s/ping, got %d", prefix, expected, w.Code)
+		}
+	}
+}
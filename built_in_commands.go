@@ -9,7 +9,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
-	"strconv"
+	"strings"
 	"time"
 
 	"github.com/mitoteam/mttools"
@@ -34,6 +34,10 @@ func (app *AppBase) buildRootCmd() {
 				if err := app.loadSettings(); err != nil {
 					return err
 				}
+
+				if err := app.initLogger(); err != nil {
+					return err
+				}
 			} else {
 				//do not require settings loading just for certain commands
 				if cmd.Name() != "init" && cmd.Name() != "version" {
@@ -152,12 +156,18 @@ available options listed here with its default values. Recommendation is to edit
 want to change and remove all others with default values to keep this as simple as possible.
 `
 
-			if err := app.saveSettings(comment); err != nil {
+			if err := app.SaveSettings(comment); err != nil {
 				return err
 			}
 
 			fmt.Println("Default app settings written to " + app.AppSettingsFilename)
 
+			for _, m := range app.modules {
+				if err := m.Init(); err != nil {
+					return fmt.Errorf("module %s init: %w", m.ModuleName(), err)
+				}
+			}
+
 			if app.InitF != nil {
 				if err := app.InitF(); err != nil {
 					return err
@@ -189,6 +199,23 @@ func (app *AppBase) buildInfoCmd() *cobra.Command {
 			fmt.Print("================================\n")
 			app.printSettings()
 
+			// Web server
+			fmt.Print("================================\n")
+			fmt.Printf("Listen: %s\n", strings.Join(app.listenAddresses(), ", "))
+			app.printTlsInfo()
+
+			if app.baseSettings.AdminListen != "" {
+				fmt.Printf("Admin listen: %s\n", app.baseSettings.AdminListen)
+			}
+
+			if app.baseSettings.ControlSocket != "" {
+				fmt.Printf("Control socket: %s\n", app.baseSettings.ControlSocket)
+			}
+
+			for _, m := range app.modules {
+				m.PrintInfo()
+			}
+
 			if app.PrintInfoF != nil {
 				app.PrintInfoF()
 			}
@@ -204,26 +231,93 @@ func (app *AppBase) buildRunCmd() *cobra.Command {
 		Short: "Runs webserver",
 
 		RunE: func(cmd *cobra.Command, args []string) error {
-			address := app.baseSettings.WebserverHostname +
-				":" + strconv.FormatUint(uint64(app.baseSettings.WebserverPort), 10)
+			app.setGinWriters()
 
 			//Graceful shutdown according to https://github.com/gorilla/mux#graceful-shutdown
 			httpSrv := &http.Server{
-				Addr:         address,
 				WriteTimeout: time.Second * 10,
 				ReadTimeout:  time.Second * 20,
 				IdleTimeout:  time.Second * 60,
-				Handler:      app.webRouter.Handler(),
+				Handler:      app.Handler(),
 				BaseContext:  func(l net.Listener) context.Context { return app.BaseContext },
 			}
 
-			log.Printf("Starting up web server at http://%s\nPress Ctrl + C to stop it.\n", address)
+			useTls := app.IsTlsEnabled()
 
-			go func() {
-				if err := httpSrv.ListenAndServe(); err != nil {
-					log.Println(err)
+			if useTls {
+				tlsConfig, err := app.buildTlsConfig()
+				if err != nil {
+					return errors.Join(err, app.Shutdown())
 				}
-			}()
+
+				httpSrv.TLSConfig = tlsConfig
+			}
+
+			listeners, err := app.openListeners()
+			if err != nil {
+				return errors.Join(err, app.Shutdown())
+			}
+
+			serverErrChannel := make(chan error, len(listeners)+3)
+
+			// one server for all listeners, httpSrv.Shutdown() closes all of them gracefully
+			for _, l := range listeners {
+				app.logger.Info("Web server listening", "component", LogComponentWeb, "url", listenerUrl(l, useTls))
+
+				go func() {
+					var err error
+
+					if useTls {
+						err = httpSrv.ServeTLS(l, "", "") // certificate is provided by TLSConfig.GetCertificate
+					} else {
+						err = httpSrv.Serve(l)
+					}
+
+					if err != nil && !errors.Is(err, http.ErrServerClosed) {
+						l.Close()
+						serverErrChannel <- err
+					}
+				}()
+			}
+
+			app.logger.Info("Web server started. Press Ctrl + C to stop it.", "component", LogComponentWeb)
+
+			redirectSrv := app.buildHttpRedirectServer()
+
+			if redirectSrv != nil {
+				app.logger.Info("Starting HTTP redirect listener", "component", LogComponentWeb, "address", redirectSrv.Addr, "target", app.baseSettings.BaseUrl)
+
+				go func() {
+					if err := redirectSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+						serverErrChannel <- err
+					}
+				}()
+			}
+
+			adminSrv := app.buildAdminServer()
+
+			if adminSrv != nil {
+				app.logger.Info("Starting admin listener", "component", LogComponentWeb, "address", adminSrv.Addr)
+
+				go func() {
+					if err := adminSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+						serverErrChannel <- err
+					}
+				}()
+			}
+
+			controlSrv, controlListener, err := app.openControlSocket()
+			if err != nil {
+				serverErrChannel <- err
+			} else if controlSrv != nil {
+				app.logger.Info("Control socket listening", "component", LogComponentWeb, "path", app.baseSettings.ControlSocket)
+
+				go func() {
+					if err := controlSrv.Serve(controlListener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+						serverErrChannel <- err
+					}
+				}()
+			}
 
 			cancel_channel := make(chan os.Signal, 1)
 
@@ -231,17 +325,53 @@ func (app *AppBase) buildRunCmd() *cobra.Command {
 			// SIGKILL, SIGQUIT or SIGTERM (Ctrl+/) will not be caught.
 			signal.Notify(cancel_channel, os.Interrupt, os.Kill)
 
-			// Block execution until we receive our signal.
-			<-cancel_channel
+			var serverErr error
+
+			// Block execution until we receive our signal or web server fails.
+			select {
+			case <-cancel_channel:
+			case serverErr = <-serverErrChannel:
+				app.logger.Error("Web server failed", "component", LogComponentWeb, "error", serverErr)
+			}
+
+			app.logger.Info("Shutting down web server", "component", LogComponentWeb)
+
+			// let everyone using BaseContext know we are shutting down
+			app.cancelBase()
+
+			// readiness endpoint fails now, keep serving until load balancers notice it (second signal skips waiting)
+			if delay := time.Duration(app.baseSettings.WebserverShutdownDelay) * time.Second; delay > 0 && serverErr == nil {
+				app.logger.Info("Waiting for load balancers to stop sending requests", "component", LogComponentWeb, "delay", delay)
 
-			log.Println("Shutting down web server")
+				select {
+				case <-time.After(delay):
+				case <-cancel_channel:
+				}
+			}
 
-			// Create a deadline to wait for (10s).
-			ctx, cancel := context.WithTimeout(app.BaseContext, app.ShutdownTimeout)
+			// Create a deadline to wait for (10s). BaseContext is already canceled so it can not be used here.
+			ctx, cancel := context.WithTimeout(context.Background(), app.ShutdownTimeout)
 			defer cancel()
 
+			if redirectSrv != nil {
+				redirectSrv.Shutdown(ctx)
+			}
+
+			if adminSrv != nil {
+				adminSrv.Shutdown(ctx)
+			}
+
+			if controlSrv != nil {
+				controlSrv.Shutdown(ctx)
+			}
+
 			if err := httpSrv.Shutdown(ctx); err != nil {
-				log.Fatal("Server forced to shutdown:", err)
+				app.logger.Error("Server forced to shutdown", "component", LogComponentWeb, "error", err)
+			}
+
+			if serverErr != nil {
+				// PostRunE is not called by cobra if RunE fails, so do shutdown procedures here
+				return errors.Join(serverErr, app.Shutdown())
 			}
 
 			return nil
@@ -249,30 +379,12 @@ func (app *AppBase) buildRunCmd() *cobra.Command {
 
 		// Do startup procedures
 		PreRunE: func(cmd *cobra.Command, args []string) error {
-			log.Printf("%s version: %s\n", app.AppName, app.Version)
-
-			app.buildWebRouter()
-
-			var err error
-
-			if app.PreRunF != nil {
-				err = app.PreRunF()
-			}
-
-			return err
+			return app.startup()
 		},
 
 		// Do shutdown procedures
 		PostRunE: func(cmd *cobra.Command, args []string) error {
-			var err error
-
-			if app.PostRunF != nil {
-				err = app.PostRunF()
-			}
-
-			log.Println("Shutdown complete")
-
-			return err
+			return app.Shutdown()
 		},
 	}
 
@@ -283,6 +395,82 @@ func (app *AppBase) buildRunCmd() *cobra.Command {
 		false,
 		"Extended web router queries logging.",
 	)
+	cmd.PersistentFlags().MarkDeprecated("query-log", "use 'access_log: log' setting instead")
+
+	return cmd
+}
+
+func (app *AppBase) buildMaintenanceCmd() *cobra.Command {
+	var (
+		message    string
+		retryAfter int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "maintenance on|off|status",
+		Short: "Switches maintenance mode (running instances pick it up within a second)",
+
+		ValidArgs: []string{"on", "off", "status"},
+		Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch args[0] {
+			case "on":
+				if err := app.SetMaintenance(&MaintenanceState{Message: message, RetryAfter: retryAfter}); err != nil {
+					return err
+				}
+			case "off":
+				if err := app.SetMaintenance(nil); err != nil {
+					return err
+				}
+			}
+
+			if state := app.Maintenance(); state != nil {
+				fmt.Printf("Maintenance mode: on since %s\n", state.Since.Format(time.DateTime))
+
+				if state.Message != "" {
+					fmt.Printf("Message: %s\n", state.Message)
+				}
+			} else {
+				fmt.Println("Maintenance mode: off")
+			}
+
+			fmt.Printf("Flag file: %s\n", app.MaintenanceFile())
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&message, "message", "", "Message shown to users")
+	cmd.Flags().IntVar(&retryAfter, "retry-after", defaultMaintenanceRetryAfter, "Retry-After header value (seconds)")
+
+	return cmd
+}
+
+// `ctl` command with subcommand for every admin command (built-in and registered by application).
+func (app *AppBase) buildCtlCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ctl",
+		Short: "Sends admin commands to running instance (through control_socket or admin_listen)",
+	}
+
+	for _, ac := range app.adminCommands {
+		cmd.AddCommand(&cobra.Command{
+			Use:   ac.name + " [args...]",
+			Short: ac.description,
+
+			RunE: func(cmd *cobra.Command, args []string) error {
+				output, err := app.callAdminCommand(ac.name, args)
+				if err != nil {
+					return err
+				}
+
+				fmt.Print(output)
+
+				return nil
+			},
+		})
+	}
 
 	return cmd
 }
@@ -0,0 +1,209 @@
+package goapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+)
+
+// Application component with managed lifecycle (database, worker pools, background loops etc).
+// Components are started by `run` command in dependency order before web server starts and
+// stopped in reverse order after web server is drained.
+type Component interface {
+	Name() string                    // unique component name
+	Start(ctx context.Context) error // should not block, start goroutines if long-running work needed
+	Stop(ctx context.Context) error  // ctx has component stop timeout set
+}
+
+// Optional interface for components that require other components to be started first.
+type ComponentWithDependencies interface {
+	Component
+
+	DependsOn() []string // names of components that should be started before this one
+}
+
+// Simple Component implementation for cases when creating separate type is overkill.
+type ComponentFuncs struct {
+	ComponentName string
+	Dependencies  []string
+
+	StartF func(ctx context.Context) error
+	StopF  func(ctx context.Context) error
+}
+
+func (c *ComponentFuncs) Name() string {
+	return c.ComponentName
+}
+
+func (c *ComponentFuncs) DependsOn() []string {
+	return c.Dependencies
+}
+
+func (c *ComponentFuncs) Start(ctx context.Context) error {
+	if c.StartF == nil {
+		return nil
+	}
+
+	return c.StartF(ctx)
+}
+
+func (c *ComponentFuncs) Stop(ctx context.Context) error {
+	if c.StopF == nil {
+		return nil
+	}
+
+	return c.StopF(ctx)
+}
+
+// Registers component to be started and stopped by `run` command.
+func (app *AppBase) AddComponent(c Component) *AppBase {
+	if c.Name() == "" {
+		log.Panicln("component name should not be empty")
+	}
+
+	for _, existing := range app.components {
+		if existing.Name() == c.Name() {
+			log.Panicf("component %s already registered", c.Name())
+		}
+	}
+
+	app.components = append(app.components, c)
+
+	return app //for method chaining
+}
+
+// Returns registered component by name or nil if there is no such component.
+func (app *AppBase) Component(name string) Component {
+	for _, c := range app.components {
+		if c.Name() == name {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// Returns components list sorted in dependency order. Registration order is kept for
+// independent components.
+func sortComponents(list []Component) ([]Component, error) {
+	byName := make(map[string]Component, len(list))
+	for _, c := range list {
+		byName[c.Name()] = c
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[string]int, len(list))
+	sorted := make([]Component, 0, len(list))
+
+	var visit func(c Component, path []string) error
+
+	visit = func(c Component, path []string) error {
+		name := c.Name()
+
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("component dependency cycle: %v", append(path, name))
+		}
+
+		state[name] = visiting
+
+		if withDeps, ok := c.(ComponentWithDependencies); ok {
+			for _, depName := range withDeps.DependsOn() {
+				dep, exists := byName[depName]
+				if !exists {
+					return fmt.Errorf("component %s depends on unknown component %s", name, depName)
+				}
+
+				if err := visit(dep, append(path, name)); err != nil {
+					return err
+				}
+			}
+		}
+
+		state[name] = visited
+		sorted = append(sorted, c)
+
+		return nil
+	}
+
+	for _, c := range list {
+		if err := visit(c, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return sorted, nil
+}
+
+// Starts all registered components in dependency order. If some component fails to start
+// already started ones are stopped.
+func (app *AppBase) startComponents(ctx context.Context) error {
+	sorted, err := sortComponents(app.components)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range sorted {
+		app.logger.Info("Starting component", "component", c.Name())
+
+		if err := c.Start(ctx); err != nil {
+			err = fmt.Errorf("component %s failed to start: %w", c.Name(), err)
+
+			if stopErr := app.stopComponents(); stopErr != nil {
+				err = errors.Join(err, stopErr)
+			}
+
+			return err
+		}
+
+		app.startedComponents = append(app.startedComponents, c)
+	}
+
+	return nil
+}
+
+// Stops started components in reverse order. Every component gets its own ComponentStopTimeout.
+func (app *AppBase) stopComponents() error {
+	var errList []error
+
+	for _, c := range slices.Backward(app.startedComponents) {
+		app.logger.Info("Stopping component", "component", c.Name())
+
+		if err := app.stopComponent(c); err != nil {
+			app.logger.Error("Component failed to stop", "component", c.Name(), "error", err)
+			err = fmt.Errorf("component %s failed to stop: %w", c.Name(), err)
+			errList = append(errList, err)
+		}
+	}
+
+	app.startedComponents = nil
+
+	return errors.Join(errList...)
+}
+
+func (app *AppBase) stopComponent(c Component) error {
+	ctx, cancel := context.WithTimeout(context.Background(), app.ComponentStopTimeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- c.Stop(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("timeout exceeded (%s)", app.ComponentStopTimeout)
+	}
+}
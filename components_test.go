@@ -0,0 +1,66 @@
+package goapp
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestComponentsOrder(t *testing.T) {
+	var events []string
+
+	newComponent := func(name string, deps ...string) *ComponentFuncs {
+		return &ComponentFuncs{
+			ComponentName: name,
+			Dependencies:  deps,
+			StartF:        func(ctx context.Context) error { events = append(events, "start "+name); return nil },
+			StopF:         func(ctx context.Context) error { events = append(events, "stop "+name); return nil },
+		}
+	}
+
+	app := &AppBase{ComponentStopTimeout: time.Second, logger: slog.Default()}
+	app.AddComponent(newComponent("web", "db", "pool")).
+		AddComponent(newComponent("pool", "db")).
+		AddComponent(newComponent("db"))
+
+	if err := app.startComponents(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.stopComponents(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"start db", "start pool", "start web", "stop web", "stop pool", "stop db"}
+	if !slices.Equal(events, expected) {
+		t.Errorf("unexpected events order: %v", events)
+	}
+}
+
+func TestComponentsErrors(t *testing.T) {
+	app := &AppBase{ComponentStopTimeout: time.Second, logger: slog.Default()}
+	app.AddComponent(&ComponentFuncs{ComponentName: "a", Dependencies: []string{"b"}}).
+		AddComponent(&ComponentFuncs{ComponentName: "b", Dependencies: []string{"a"}})
+
+	if err := app.startComponents(context.Background()); err == nil {
+		t.Error("dependency cycle not detected")
+	}
+
+	stopped := false
+	app = &AppBase{ComponentStopTimeout: time.Second, logger: slog.Default()}
+	app.AddComponent(&ComponentFuncs{ComponentName: "ok", StopF: func(ctx context.Context) error { stopped = true; return nil }}).
+		AddComponent(&ComponentFuncs{ComponentName: "bad", StartF: func(ctx context.Context) error { return errors.New("boom") }})
+
+	if err := app.startComponents(context.Background()); err == nil {
+		t.Error("start error expected")
+	} else if err.Error() != "component bad failed to start: boom" {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	if !stopped {
+		t.Error("started component was not stopped after failure")
+	}
+}
@@ -1,8 +1,11 @@
 package goapp
 
 import (
+	"context"
 	"log"
+	"log/slog"
 	"reflect"
+	"time"
 
 	"github.com/glebarez/sqlite"
 	"github.com/mitoteam/mttools"
@@ -12,20 +15,37 @@ import (
 
 const dbFileName = "data.db"
 
-type dbSchemaType struct {
+// Database schema (registered models) and handle. Every AppBase has its own one in
+// AppBase.Database, several databases can be used in one process.
+type Database struct {
+	FileName string // sqlite database file
+
 	modelMap map[string]any // name = typename, value = empty struct of this type
 	db       *gorm.DB
+	ctx      context.Context // context for all gorm sessions
+	logger   *slog.Logger    // slog.Default() if not set by SetLogger()
+
+	gormTx *gorm.DB // prepared by PreQuery() for compatibility helpers (LoadO, LoadOL etc)
 }
 
-var DbSchema *dbSchemaType
+// Backing database of package level compatibility helpers (PreQuery, LoadO, LoadOL, SaveObject
+// etc). Applications using these helpers should set app.Database = DbSchema.
+var DbSchema *Database
 
 func init() {
-	DbSchema = &dbSchemaType{}
+	DbSchema = NewDatabase(dbFileName)
+}
 
-	DbSchema.modelMap = make(map[string]any, 0) //typeName => modelObject
+// Creates new database with empty schema. It is opened by Open() or when started as Component.
+func NewDatabase(fileName string) *Database {
+	return &Database{
+		FileName: fileName,
+		modelMap: make(map[string]any, 0), //typeName => modelObject
+		ctx:      context.Background(),
+	}
 }
 
-func (schema *dbSchemaType) AddModel(modelType reflect.Type) {
+func (schema *Database) AddModel(modelType reflect.Type) {
 	//ensure it is a struct
 	if modelType.Kind() != reflect.Struct {
 		log.Panicf("modelType %s is not a struct", modelType.String())
@@ -40,20 +60,43 @@ func (schema *dbSchemaType) AddModel(modelType reflect.Type) {
 	schema.modelMap[modelType.String()] = reflect.New(modelType).Elem().Interface()
 }
 
-func (schema *dbSchemaType) HasModel(modelType reflect.Type) bool {
+func (schema *Database) HasModel(modelType reflect.Type) bool {
 	_, exists := schema.modelMap[modelType.String()]
 	return exists
 }
 
-func (schema *dbSchemaType) Db() *gorm.DB {
+func (schema *Database) Db() *gorm.DB {
 	return schema.db
 }
 
-func (db_schema *dbSchemaType) Open() error {
+// Sets context for all database queries. Queries are canceled when ctx is done.
+func (schema *Database) SetContext(ctx context.Context) {
+	schema.ctx = ctx
+
+	if schema.db != nil {
+		schema.db = schema.db.WithContext(ctx)
+	}
+}
+
+// Sets logger for database messages and gorm. AppBase sets its logger on startup.
+func (schema *Database) SetLogger(logger *slog.Logger) {
+	schema.logger = logger
+}
+
+func (schema *Database) log() *slog.Logger {
+	logger := schema.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return logger.With("component", LogComponentDb)
+}
+
+func (db_schema *Database) Open() error {
 	var err error
 
-	db_schema.db, err = gorm.Open(sqlite.Open(dbFileName), &gorm.Config{
-		//Logger: logger.Default.LogMode(logger.Warn),
+	db_schema.db, err = gorm.Open(sqlite.Open(db_schema.FileName), &gorm.Config{
+		Logger: &gormSlogLogger{logger: db_schema.log(), slowThreshold: 200 * time.Millisecond},
 		NamingStrategy: schema.NamingStrategy{
 			SingularTable: true, // use singular table name, table for `User` would be `user` with this option enabled
 		},
@@ -63,30 +106,54 @@ func (db_schema *dbSchemaType) Open() error {
 		return err
 	}
 
-	log.Printf("Database %s opened\n", dbFileName)
+	db_schema.db = db_schema.db.WithContext(db_schema.ctx)
+
+	db_schema.log().Info("Database opened", "file", db_schema.FileName)
 
 	// Migrate the schema
 	//log.Printf("DBG: %+v\n", db_schema.modelMap)
 	for name, modelObject := range db_schema.modelMap {
 		//log.Printf("DBG: %s %+v\n", name, modelObject)
 		if err := db_schema.db.AutoMigrate(modelObject); err != nil {
-			log.Printf("ERROR migrating %s: %s", name, err.Error())
+			db_schema.log().Error("Model migration failed", "model", name, "error", err)
 		}
 	}
 
-	log.Printf("Database migration done (schema model count: %d)\n", len(db_schema.modelMap))
+	db_schema.log().Info("Database migration done", "model_count", len(db_schema.modelMap))
 
 	return nil
 }
 
-func (schema *dbSchemaType) Close() {
+func (schema *Database) Close() {
+	if schema.db == nil {
+		return
+	}
+
 	sqlDB, err := schema.db.DB()
 
-	if err != nil {
+	if err == nil {
 		sqlDB.Close()
 	}
 
-	log.Printf("Database %s closed\n", dbFileName)
+	schema.log().Info("Database closed", "file", schema.FileName)
 
 	schema.db = nil
 }
+
+// Component interface implementation to let AppBase open and close database:
+// app.AddComponent(app.Database)
+func (schema *Database) Name() string {
+	return "database"
+}
+
+func (schema *Database) Start(ctx context.Context) error {
+	schema.SetContext(ctx)
+
+	return schema.Open()
+}
+
+func (schema *Database) Stop(ctx context.Context) error {
+	schema.Close()
+
+	return nil
+}
@@ -18,51 +18,128 @@ type BaseModel struct {
 	UpdatedAt time.Time
 }
 
-// gorm TX object. Prepared in PreQuery(), used in LoadObject, LoadOL, CountOL
-var gormTx *gorm.DB
+// Scoped query for model type ModelT in database db. It is an alternative to package level
+// helpers (PreQuery, LoadO, LoadOL etc) which use shared DbSchema and global prepared TX.
+//
+//	list := goapp.NewQuery[User](app.Database).Where("active = ?", true).List()
+type Query[ModelT any] struct {
+	db *Database
+	tx *gorm.DB
+}
+
+// Prepares query for model (O)bjects. Returns query with nil TX if database is not opened or
+// ModelT is not registered model (all loading methods return empty results in that case).
+func NewQuery[ModelT any](db *Database) *Query[ModelT] {
+	q := &Query[ModelT]{db: db}
 
-// Prepares gorm TX for loading model (O)bjects (L)ist.
-// Returned TX can be used to apply conditions and other gorm query clauses.
-func PreQuery[ModelT any]() (tx *gorm.DB) {
 	var modelObject ModelT
 
-	if !checkSchemaModelType(reflect.TypeOf(modelObject)) {
-		return nil
+	if db.checkSchemaModelType(reflect.TypeOf(modelObject)) {
+		q.tx = db.Db().Model(&modelObject)
 	}
 
-	gormTx = DbSchema.Db().Model(&modelObject)
-	return gormTx
+	return q
 }
 
-// Loads model object by ID. Returns nil if object was not loaded.
-func LoadO[ModelT any](id any) (r *ModelT) {
-	defer func() { gormTx = nil }()
+// Returns prepared gorm TX to apply conditions and other gorm query clauses. Can be nil.
+func (q *Query[ModelT]) Tx() *gorm.DB {
+	return q.tx
+}
+
+// Adds WHERE condition.
+func (q *Query[ModelT]) Where(query any, args ...any) *Query[ModelT] {
+	if q.tx != nil {
+		q.tx = q.tx.Where(query, args...)
+	}
 
+	return q //for method chaining
+}
+
+// Loads model object by ID. Returns nil if object was not loaded.
+func (q *Query[ModelT]) Load(id any) *ModelT {
 	typedId, ok := mttools.AnyToInt64Ok(id)
 
-	if !ok || typedId == 0 { //id is empty
+	if !ok || typedId == 0 || q.tx == nil { //id is empty or unable to prepare
 		return nil
 	}
 
-	if gormTx == nil { // gormTx is not prepared
-		if gormTx = PreQuery[ModelT](); gormTx == nil { //unable to prepare
-			return nil
-		}
+	var modelObject ModelT
+
+	if err := q.tx.First(&modelObject, typedId).Error; err != nil {
+		q.db.logQueryError(err)
+		return nil
 	}
 
-	var modelObject ModelT
+	return &modelObject
+}
 
-	if err := gormTx.First(&modelObject, typedId).Error; err != nil {
-		if !errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Println("Query ERROR: " + err.Error())
-		}
+// Loads first available model object. Returns nil if object was not loaded.
+func (q *Query[ModelT]) First() *ModelT {
+	if q.tx == nil {
+		return nil
+	}
 
+	var modelObject ModelT
+
+	if err := q.tx.First(&modelObject).Error; err != nil {
+		q.db.logQueryError(err)
 		return nil
 	}
 
 	return &modelObject
 }
 
+// Loads model (O)bjects (L)ist.
+func (q *Query[ModelT]) List() (list []*ModelT) {
+	list = []*ModelT{} //empty list by default
+
+	if q.tx == nil {
+		return list
+	}
+
+	if err := q.tx.Find(&list).Error; err != nil {
+		q.db.logQueryError(err)
+	}
+
+	return list
+}
+
+// Counts records for model (O)bjects.
+func (q *Query[ModelT]) Count() (cnt int64) {
+	if q.tx == nil {
+		return 0
+	}
+
+	if err := q.tx.Count(&cnt).Error; err != nil {
+		q.db.logQueryError(err)
+	}
+
+	return cnt
+}
+
+// Prepares gorm TX for loading model (O)bjects (L)ist from DbSchema.
+// Returned TX can be used to apply conditions and other gorm query clauses.
+func PreQuery[ModelT any]() (tx *gorm.DB) {
+	DbSchema.gormTx = NewQuery[ModelT](DbSchema).Tx()
+	return DbSchema.gormTx
+}
+
+// Returns query using TX prepared by PreQuery() (or new one) and resets prepared TX.
+func preparedQuery[ModelT any]() *Query[ModelT] {
+	defer func() { DbSchema.gormTx = nil }()
+
+	if DbSchema.gormTx == nil {
+		return NewQuery[ModelT](DbSchema)
+	}
+
+	return &Query[ModelT]{db: DbSchema, tx: DbSchema.gormTx}
+}
+
+// Loads model object by ID from DbSchema. Returns nil if object was not loaded.
+func LoadO[ModelT any](id any) (r *ModelT) {
+	return preparedQuery[ModelT]().Load(id)
+}
+
 // Works like LoadO() but panics if object was not found.
 func LoadOMust[ModelT any](id any) (r *ModelT) {
 	r = LoadO[ModelT](id)
@@ -92,32 +169,36 @@ func LoadOrCreateO[ModelT any](id any) (r *ModelT) {
 // Loads first available model object. Conditions can be set in PreQuery().
 // Returns nil if object was not loaded.
 func FirstO[ModelT any]() (r *ModelT) {
-	defer func() { gormTx = nil }()
-
-	if gormTx == nil { // gormTx is not prepared
-		if gormTx = PreQuery[ModelT](); gormTx == nil { //unable to prepare
-			return nil
-		}
-	}
+	return preparedQuery[ModelT]().First()
+}
 
-	var modelObject ModelT
+// Deletes object from DbSchema. Returns error if something goes wrong.
+func DeleteObject(modelObject any) error {
+	return DbSchema.DeleteObject(modelObject)
+}
 
-	if err := gormTx.First(&modelObject).Error; err != nil {
-		if !errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Println("Query ERROR: " + err.Error())
-		}
+// Saves object to DbSchema. Returns false if something goes wrong.
+func SaveObject(modelObject any) bool {
+	return DbSchema.SaveObject(modelObject)
+}
 
-		return nil
-	}
+// Loads model (O)bjects (L)ist using prepared gorm TX - PreQuery().
+// if gorm TX was not prepared, empty one is created (selecting all model objects)
+func LoadOL[ModelT any]() (list []*ModelT) {
+	return preparedQuery[ModelT]().List()
+}
 
-	return &modelObject
+// Counts records for model (O)bjects using prepared gorm TX - PreQuery().
+// if gorm TX was not prepared, empty one is created (counting all model objects)
+func CountOL[ModelT any]() (cnt int64) {
+	return preparedQuery[ModelT]().Count()
 }
 
-// Deletes object. Returns false if something goes wrong.
-func DeleteObject(modelObject any) error {
+// Deletes object. Returns error if something goes wrong.
+func (schema *Database) DeleteObject(modelObject any) error {
 	var t reflect.Type
 
-	if t, modelObject = modelObjectReflection(modelObject); t == nil {
+	if t, modelObject = schema.modelObjectReflection(modelObject); t == nil {
 		return errors.New("modelObject is not valid schema object")
 	}
 
@@ -127,11 +208,11 @@ func DeleteObject(modelObject any) error {
 		return errors.New("modelObject has ID=0")
 	}
 
-	if err := DbSchema.Db().Delete(modelObject).Error; err != nil {
+	if err := schema.Db().Delete(modelObject).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil
 		} else {
-			log.Println("Query ERROR: " + err.Error())
+			schema.logQueryError(err)
 			return err
 		}
 	}
@@ -140,77 +221,31 @@ func DeleteObject(modelObject any) error {
 }
 
 // Saves object. Returns false if something goes wrong.
-func SaveObject(modelObject any) bool {
+func (schema *Database) SaveObject(modelObject any) bool {
 	var t reflect.Type
 
-	if t, modelObject = modelObjectReflection(modelObject); t == nil {
+	if t, modelObject = schema.modelObjectReflection(modelObject); t == nil {
 		// not a schema model object
 		return false
 	}
 
-	//if err := DbSchema.Db().Save(v.Interface()).Error; err != nil {
-	if err := DbSchema.Db().Save(modelObject).Error; err != nil {
-		if !errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Println("Query ERROR: " + err.Error())
-		}
-
+	if err := schema.Db().Save(modelObject).Error; err != nil {
+		schema.logQueryError(err)
 		return false
 	}
 
 	return true
 }
 
-// Loads model (O)bjects (L)ist using prepared gorm TX - PreQuery().
-// if gorm TX was not prepared, empty one is created (selecting all model objects)
-func LoadOL[ModelT any]() (list []*ModelT) {
-	list = []*ModelT{} //empty list by default
-	defer func() { gormTx = nil }()
-
-	if gormTx == nil {
-		if gormTx = PreQuery[ModelT](); gormTx == nil {
-			return
-		}
-	}
-
-	if err := gormTx.Find(&list).Error; err != nil {
-		if !errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Println("Query ERROR: " + err.Error())
-		}
-	}
-
-	return list
-}
-
-// Counts records for model (O)bjects using prepared gorm TX - PreQuery().
-// if gorm TX was not prepared, empty one is created (counting all model objects)
-func CountOL[ModelT any]() (cnt int64) {
-	cnt = 0
-	defer func() { gormTx = nil }()
-
-	if gormTx == nil {
-		if gormTx = PreQuery[ModelT](); gormTx == nil {
-			return
-		}
-	}
-
-	if err := gormTx.Count(&cnt).Error; err != nil {
-		if !errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Println("Query ERROR: " + err.Error())
-		}
-	}
-
-	return cnt
-}
-
 // Checks if t is type of registered model struct
-func checkSchemaModelType(t reflect.Type) bool {
-	if DbSchema.Db() == nil {
+func (schema *Database) checkSchemaModelType(t reflect.Type) bool {
+	if schema.Db() == nil {
 		//database is not opened
 		return false
 	}
 
-	if !DbSchema.HasModel(t) {
-		log.Printf("ERROR[checkSchemaModelType]: unknown model '%s'\n", t.String())
+	if !schema.HasModel(t) {
+		schema.log().Error("Unknown schema model", "model", t.String())
 		return false
 	}
 
@@ -218,7 +253,7 @@ func checkSchemaModelType(t reflect.Type) bool {
 }
 
 // returns t  = Type of model structure, o = pointer to model object
-func modelObjectReflection(modelObject any) (t reflect.Type, o any) {
+func (schema *Database) modelObjectReflection(modelObject any) (t reflect.Type, o any) {
 	t = reflect.TypeOf(modelObject)
 
 	//check if it is a model object pointer and dereference it's type
@@ -229,9 +264,16 @@ func modelObjectReflection(modelObject any) (t reflect.Type, o any) {
 		o = reflect.ValueOf(modelObject).Addr().Interface() // modelObject is struct, return pointer to it
 	}
 
-	if !checkSchemaModelType(t) {
+	if !schema.checkSchemaModelType(t) {
 		return nil, nil
 	}
 
 	return t, o
 }
+
+// Logs query error except "record not found" one.
+func (schema *Database) logQueryError(err error) {
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		schema.log().Error("Query ERROR", "error", err)
+	}
+}
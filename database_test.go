@@ -0,0 +1,42 @@
+package goapp
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDatabaseClose(t *testing.T) {
+	db := NewDatabase(filepath.Join(t.TempDir(), "data.db"))
+
+	if err := db.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	sqlDb, err := db.Db().DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db.Close()
+
+	if db.Db() != nil {
+		t.Error("gorm handle should be reset")
+	}
+
+	// connection pool should be closed
+	if err := sqlDb.Ping(); err == nil {
+		t.Error("database connection is still open")
+	}
+
+	// closing twice is fine
+	db.Close()
+}
+
+func TestAppOwnDatabase(t *testing.T) {
+	app1 := NewAppBase(&testAppSettings{})
+	app2 := NewAppBase(&testAppSettings{})
+
+	if app1.Database == DbSchema || app2.Database == DbSchema || app1.Database == app2.Database {
+		t.Error("every application should have own database")
+	}
+}
@@ -0,0 +1,167 @@
+// Package goapptest helps to write in-process integration tests for goapp based applications.
+//
+//	func TestLogin(t *testing.T) {
+//		ta := goapptest.New(t, buildApp(), nil)
+//
+//		out, status, err := ta.CallApi("/login", map[string]any{"password": "secret"})
+//		...
+//	}
+package goapptest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitoteam/goapp"
+)
+
+type TestApp struct {
+	App    *goapp.AppBase
+	Server *httptest.Server
+	Client *http.Client // client with cookie jar, sessions are kept between requests
+	Dir    string       // temporary directory with settings file and database
+
+	csrfToken   string // last CSRF token received from API
+	csrfChecked bool   // token endpoint was called, empty csrfToken then means CSRF is disabled
+}
+
+// Starts application app with temporary settings file and isolated database. Web router is served
+// by httptest.Server. Everything is shut down by t.Cleanup().
+//
+// configure (can be nil) is called with app.AppSettings before settings file is written, use it
+// to change settings for test.
+//
+// App should use own database (app.Database), test fails if it is shared goapp.DbSchema. Tests
+// can run in parallel.
+func New(t testing.TB, app *goapp.AppBase, configure func(settings any)) *TestApp {
+	t.Helper()
+
+	ta := &TestApp{
+		App: app,
+		Dir: t.TempDir(),
+	}
+
+	if app.Database == goapp.DbSchema {
+		t.Fatal("goapptest: app uses shared goapp.DbSchema, set app.Database = goapp.NewDatabase(...)")
+	}
+
+	//isolated database file
+	prevDbFileName := app.Database.FileName
+	app.Database.FileName = filepath.Join(ta.Dir, "data.db")
+
+	//temporary settings file
+	app.AppSettingsFilename = filepath.Join(ta.Dir, "settings.yml")
+
+	if configure != nil {
+		configure(app.AppSettings)
+	}
+
+	if err := app.SaveSettings("goapptest settings"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.Startup(); err != nil {
+		app.Database.FileName = prevDbFileName
+		t.Fatal(err)
+	}
+
+	ta.Server = httptest.NewServer(app.Handler())
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ta.Client = ta.Server.Client()
+	ta.Client.Jar = jar
+
+	t.Cleanup(func() {
+		ta.Server.Close()
+
+		if err := app.Shutdown(); err != nil {
+			t.Error(err)
+		}
+
+		app.Database.FileName = prevDbFileName
+	})
+
+	return ta
+}
+
+// Returns full test server URL for path.
+func (ta *TestApp) Url(path string) string {
+	return ta.Server.URL + path
+}
+
+// Calls API handler registered for path (without WebApiPathPrefix) with data encoded as JSON.
+// Returns decoded JSON reply and HTTP status code. CSRF token is sent automatically.
+func (ta *TestApp) CallApi(path string, data any) (out map[string]any, status int, err error) {
+	if ta.App.WebApiPathPrefix == "" {
+		return nil, 0, fmt.Errorf("web API is disabled (WebApiPathPrefix is empty)")
+	}
+
+	body, err := json.Marshal(data)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// first call in session: get token from built-in endpoint (there is no one if CSRF is disabled)
+	if !ta.csrfChecked && path != goapp.CsrfTokenApiPath {
+		tokenResponse, err := ta.postApi(goapp.CsrfTokenApiPath, nil)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		tokenResponse.Body.Close()
+		ta.csrfToken = tokenResponse.Header.Get(goapp.CsrfHeaderName)
+		ta.csrfChecked = true
+	}
+
+	response, err := ta.postApi(path, body)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer response.Body.Close()
+
+	if token := response.Header.Get(goapp.CsrfHeaderName); token != "" {
+		ta.csrfToken = token
+	}
+
+	replyBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, response.StatusCode, err
+	}
+
+	if err := json.Unmarshal(replyBody, &out); err != nil {
+		return nil, response.StatusCode, fmt.Errorf("non-JSON reply (HTTP %d): %s", response.StatusCode, string(replyBody))
+	}
+
+	return out, response.StatusCode, nil
+}
+
+func (ta *TestApp) postApi(path string, body []byte) (*http.Response, error) {
+	request, err := http.NewRequest(http.MethodPost, ta.Url(ta.App.WebApiPathPrefix+path), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	if ta.csrfToken != "" {
+		request.Header.Set(goapp.CsrfHeaderName, ta.csrfToken)
+	}
+
+	return ta.Client.Do(request)
+}
+
+// Performs GET request to path.
+func (ta *TestApp) Get(path string) (*http.Response, error) {
+	return ta.Client.Get(ta.Url(path))
+}
@@ -0,0 +1,187 @@
+package goapptest
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mitoteam/goapp"
+)
+
+type testSettings struct {
+	goapp.AppSettingsBase `yaml:",inline"`
+}
+
+type testNote struct {
+	goapp.BaseModel
+
+	Text string
+}
+
+func buildTestApp() *goapp.AppBase {
+	app := goapp.NewAppBase(&testSettings{})
+	app.ExecutableName = "goapptest"
+	app.AppName = "goapptest"
+	app.WebApiPathPrefix = "/api"
+
+	app.Database = goapp.NewDatabase("data.db")
+	app.Database.AddModel(reflect.TypeFor[testNote]())
+	app.AddComponent(app.Database)
+
+	app.BuildWebRouterF = func(r *gin.Engine) {
+		r.GET("/hello", func(c *gin.Context) { c.String(200, "hello") })
+	}
+
+	app.ApiHandler("/counter", func(r *goapp.ApiRequest) error {
+		counter, _ := r.SessionGet("counter").(int)
+		counter++
+		r.SessionSet("counter", counter)
+		r.Session().Save()
+
+		r.SetOutData("counter", counter)
+		return nil
+	})
+
+	app.ApiHandler("/note", func(r *goapp.ApiRequest) error {
+		note := &testNote{Text: r.GetInData("text")}
+		app.Database.SaveObject(note)
+
+		r.SetOutData("count", goapp.NewQuery[testNote](app.Database).Where("text = ?", note.Text).Count())
+		return nil
+	})
+
+	return app
+}
+
+func TestHarness(t *testing.T) {
+	t.Parallel()
+
+	ta := New(t, buildTestApp(), nil)
+
+	response, err := ta.Get("/hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, _ := io.ReadAll(response.Body)
+	response.Body.Close()
+
+	if string(body) != "hello" {
+		t.Errorf("unexpected /hello reply: %s", body)
+	}
+
+	for i := 1; i <= 2; i++ {
+		out, status, err := ta.CallApi("/counter", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if status != 200 || out["status"] != "ok" || out["counter"] != float64(i) {
+			t.Errorf("unexpected /counter reply: %d %v", status, out)
+		}
+	}
+
+	out, _, err := ta.CallApi("/note", map[string]any{"text": "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out["count"] != float64(1) {
+		t.Errorf("unexpected /note reply: %v", out)
+	}
+
+	if _, status, _ := ta.CallApi("/unknown", nil); status != 404 {
+		t.Errorf("unexpected status for unknown API path: %d", status)
+	}
+
+	response, err = ta.Get("/readyz")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, _ = io.ReadAll(response.Body)
+	response.Body.Close()
+
+	if response.StatusCode != 200 || !strings.Contains(string(body), `"database":{"status":"ok"`) {
+		t.Errorf("unexpected /readyz reply: %d %s", response.StatusCode, body)
+	}
+}
+
+func TestParallelApps(t *testing.T) {
+	t.Parallel()
+
+	one := New(t, buildTestApp(), nil)
+	two := New(t, buildTestApp(), nil)
+
+	for range 2 {
+		one.CallApi("/note", map[string]any{"text": "same"})
+	}
+
+	out, _, err := two.CallApi("/note", map[string]any{"text": "same"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out["count"] != float64(1) {
+		t.Errorf("databases are not isolated: %v", out)
+	}
+}
+
+type testModule struct {
+	goapp.AppModuleBase
+
+	settings struct {
+		Greeting string `yaml:"greeting"`
+	}
+}
+
+func (m *testModule) ModuleName() string { return "greeter" }
+func (m *testModule) Settings() any      { return &m.settings }
+
+type countingTransport struct {
+	paths []string
+}
+
+func (ct *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	ct.paths = append(ct.paths, r.URL.Path)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestModuleSettingsAndCsrfDisabled(t *testing.T) {
+	t.Parallel()
+
+	app := buildTestApp()
+	module := &testModule{}
+	module.settings.Greeting = "hi"
+	app.UseModule(module)
+
+	ta := New(t, app, func(settings any) {
+		settings.(*testSettings).WebApiCsrfDisabled = true
+	})
+
+	data, err := os.ReadFile(app.AppSettingsFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(data), "greeter:\n    greeting: hi") {
+		t.Errorf("settings file should contain module section: %s", data)
+	}
+
+	transport := &countingTransport{}
+	ta.Client.Transport = transport
+
+	for range 2 {
+		if _, status, err := ta.CallApi("/counter", nil); err != nil || status != 200 {
+			t.Fatalf("unexpected /counter reply: %d %v", status, err)
+		}
+	}
+
+	if len(transport.paths) != 3 {
+		t.Errorf("CSRF token endpoint should be called once: %v", transport.paths)
+	}
+}
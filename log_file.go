@@ -0,0 +1,271 @@
+package goapp
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mitoteam/mttools"
+)
+
+const logFileArchiveTimeFormat = "20060102-150405"
+
+// Log file writer with rotation by size and age. Rotated files are gzip-compressed, only
+// MaxBackups newest archives are kept.
+type LogFile struct {
+	Path       string
+	MaxSize    int64         // rotate when file gets bigger than this (bytes), 0 = no size limit
+	MaxAge     time.Duration // rotate when file is older than this, 0 = no age limit
+	MaxBackups int           // number of compressed archives to keep, 0 = keep all
+
+	mutex     sync.Mutex
+	file      *os.File
+	size      int64
+	createdAt time.Time // current file creation time, kept in statePath() file
+
+	compressWg sync.WaitGroup
+}
+
+// Opens (or creates) log file for appending.
+func OpenLogFile(path string, maxSize int64, maxAge time.Duration, maxBackups int) (*LogFile, error) {
+	lf := &LogFile{
+		Path:       path,
+		MaxSize:    maxSize,
+		MaxAge:     maxAge,
+		MaxBackups: maxBackups,
+	}
+
+	if err := lf.open(); err != nil {
+		return nil, err
+	}
+
+	return lf, nil
+}
+
+func (lf *LogFile) Write(p []byte) (n int, err error) {
+	lf.mutex.Lock()
+	defer lf.mutex.Unlock()
+
+	if lf.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	if lf.needsRotation(int64(len(p))) {
+		if err := lf.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err = lf.file.Write(p)
+	lf.size += int64(n)
+
+	return n, err
+}
+
+// Closes and opens file again. Used to let external tools (logrotate) move file away.
+func (lf *LogFile) Reopen() error {
+	lf.mutex.Lock()
+	defer lf.mutex.Unlock()
+
+	lf.close()
+
+	return lf.open()
+}
+
+// Closes file and waits for pending archives compression.
+func (lf *LogFile) Close() error {
+	lf.mutex.Lock()
+	err := lf.close()
+	lf.mutex.Unlock()
+
+	lf.compressWg.Wait()
+
+	return err
+}
+
+func (lf *LogFile) open() error {
+	if dir := filepath.Dir(lf.Path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.OpenFile(lf.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	lf.file = f
+	lf.size = info.Size()
+
+	if err := lf.loadCreatedAt(); err != nil {
+		lf.close()
+		return err
+	}
+
+	return nil
+}
+
+// Path of rotation state file keeping current log file creation time. Modification time can not
+// be used for age: it is the time of the last write.
+func (lf *LogFile) statePath() string {
+	return lf.Path + ".created"
+}
+
+// Reads current file creation time from rotation state file. New (empty) files and files without
+// valid state are considered created now.
+func (lf *LogFile) loadCreatedAt() error {
+	if lf.size > 0 {
+		if data, err := os.ReadFile(lf.statePath()); err == nil {
+			if createdAt, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(string(data))); err == nil {
+				lf.createdAt = createdAt
+				return nil
+			}
+		}
+	}
+
+	lf.createdAt = time.Now()
+
+	return os.WriteFile(lf.statePath(), []byte(lf.createdAt.Format(time.RFC3339Nano)+"\n"), 0644)
+}
+
+func (lf *LogFile) close() error {
+	if lf.file == nil {
+		return nil
+	}
+
+	err := lf.file.Close()
+	lf.file = nil
+
+	return err
+}
+
+func (lf *LogFile) needsRotation(writeSize int64) bool {
+	if lf.size == 0 {
+		return false // never rotate empty file
+	}
+
+	if lf.MaxSize > 0 && lf.size+writeSize > lf.MaxSize {
+		return true
+	}
+
+	if lf.MaxAge > 0 && time.Since(lf.createdAt) > lf.MaxAge {
+		return true
+	}
+
+	return false
+}
+
+// Renames current file, opens new one and compresses renamed file in background.
+func (lf *LogFile) rotate() error {
+	if err := lf.close(); err != nil {
+		return err
+	}
+
+	archivePath := lf.Path + "." + time.Now().Format(logFileArchiveTimeFormat)
+
+	// several rotations in one second
+	for i := 1; mttools.IsFileExists(archivePath) || mttools.IsFileExists(archivePath+".gz"); i++ {
+		archivePath = fmt.Sprintf("%s.%s-%d", lf.Path, time.Now().Format(logFileArchiveTimeFormat), i)
+	}
+
+	if err := os.Rename(lf.Path, archivePath); err != nil {
+		return err
+	}
+
+	if err := lf.open(); err != nil {
+		return err
+	}
+
+	lf.compressWg.Add(1)
+
+	go func() {
+		defer lf.compressWg.Done()
+
+		if err := gzipFile(archivePath); err != nil {
+			fmt.Fprintf(os.Stderr, "log file %s compression failed: %s\n", archivePath, err)
+		}
+
+		lf.removeOldArchives()
+	}()
+
+	return nil
+}
+
+// Returns compressed archives list, oldest first.
+func (lf *LogFile) Archives() []string {
+	list, _ := filepath.Glob(lf.Path + ".*.gz")
+
+	modTime := func(path string) time.Time {
+		if info, err := os.Stat(path); err == nil {
+			return info.ModTime()
+		}
+
+		return time.Time{}
+	}
+
+	slices.SortStableFunc(list, func(a, b string) int {
+		return modTime(a).Compare(modTime(b))
+	})
+
+	return list
+}
+
+func (lf *LogFile) removeOldArchives() {
+	if lf.MaxBackups <= 0 {
+		return
+	}
+
+	list := lf.Archives()
+
+	for len(list) > lf.MaxBackups {
+		os.Remove(list[0])
+		list = list[1:]
+	}
+}
+
+func gzipFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(dst)
+
+	if _, err := io.Copy(zw, src); err != nil {
+		dst.Close()
+		os.Remove(dst.Name())
+		return err
+	}
+
+	if err := zw.Close(); err != nil {
+		dst.Close()
+		return err
+	}
+
+	if err := dst.Close(); err != nil {
+		return err
+	}
+
+	src.Close()
+
+	return os.Remove(path)
+}
@@ -0,0 +1,99 @@
+package goapp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitoteam/mttools"
+)
+
+func TestLogFileRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	lf, err := OpenLogFile(path, 100, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	line := strings.Repeat("x", 59) + "\n"
+
+	for range 8 {
+		if _, err := lf.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := lf.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if archives := lf.Archives(); len(archives) != 2 {
+		t.Errorf("expected 2 archives, got %d: %v", len(archives), archives)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != line {
+		t.Errorf("unexpected current log file content: %q", string(data))
+	}
+}
+
+func TestLogFileAgeOfExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	// file written recently, but created two hours ago
+	if err := os.WriteFile(path, []byte("old line\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	createdAt := time.Now().Add(-2 * time.Hour).Format(time.RFC3339Nano)
+	if err := os.WriteFile(path+".created", []byte(createdAt+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	lf, err := OpenLogFile(path, 0, time.Hour, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := lf.Write([]byte("new line\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := lf.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if archives := lf.Archives(); len(archives) != 1 {
+		t.Errorf("file older than max age should be rotated, archives: %v", archives)
+	}
+
+	// existing file without rotation state is considered created on open
+	lf, err = OpenLogFile(path, 0, time.Hour, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.Remove(path + ".created")
+	lf.Reopen()
+
+	if _, err := lf.Write([]byte("another line\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	lf.Close()
+
+	if archives := lf.Archives(); len(archives) != 1 {
+		t.Errorf("file without rotation state should not be rotated, archives: %v", archives)
+	}
+
+	if !mttools.IsFileExists(path + ".created") {
+		t.Error("rotation state file should be created")
+	}
+}
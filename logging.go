@@ -0,0 +1,268 @@
+package goapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// Component names used as "component" attribute of log records.
+const (
+	LogComponentApp    = "app"
+	LogComponentDb     = "db"
+	LogComponentWeb    = "web"
+	LogComponentApi    = "api"
+	LogComponentWorker = "worker"
+)
+
+// Application logger. Configured from settings when they are loaded, logs to stderr with
+// default options before that.
+func (app *AppBase) Logger() *slog.Logger {
+	return app.logger
+}
+
+// Returns application logger with "component" attribute set.
+func (app *AppBase) componentLogger(component string) *slog.Logger {
+	return app.logger.With("component", component)
+}
+
+// gin context key with application logger
+const loggerGinKey = "goapp.logger"
+
+// gin middleware making application logger available to package-level helpers (CsrfToken etc).
+func (app *AppBase) loggerMiddleware(c *gin.Context) {
+	c.Set(loggerGinKey, app.logger)
+}
+
+// Returns application logger for request with "component" attribute set. Request should be
+// served by application router (see loggerMiddleware()).
+func ginComponentLogger(c *gin.Context, component string) *slog.Logger {
+	logger, ok := c.Value(loggerGinKey).(*slog.Logger)
+	if !ok {
+		log.Panicf("no application logger in request context, request is not served by application router")
+	}
+
+	return logger.With("component", component)
+}
+
+// Returns io.Writer sending gin output (recovered panics, warnings) to application log.
+func (app *AppBase) ginWriter(level slog.Level) io.Writer {
+	return &slogWriter{logger: app.componentLogger(LogComponentWeb), level: level}
+}
+
+// Sends gin package-level output (debug messages and warnings) to application log. gin writers
+// are process-wide, so it is done by `run` command only: application owns the process then.
+func (app *AppBase) setGinWriters() {
+	gin.DefaultWriter = app.ginWriter(slog.LevelInfo)
+	gin.DefaultErrorWriter = app.ginWriter(slog.LevelError)
+}
+
+// Parses level name (debug, info, warn, error).
+func ParseLogLevel(name string) (slog.Level, error) {
+	var level slog.Level
+
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return level, fmt.Errorf("unknown log level '%s'", name)
+	}
+
+	return level, nil
+}
+
+// Sets current log level. Can be changed at runtime.
+func (app *AppBase) SetLogLevel(level slog.Level) {
+	app.logLevel.Set(level)
+}
+
+// (Re)creates application logger from settings. Logger is not set as slog default or gin writer,
+// it is passed to routers, database and worker pools so several apps can live in one process.
+func (app *AppBase) initLogger() error {
+	level, err := ParseLogLevel(app.baseSettings.LogLevel)
+	if err != nil {
+		return err
+	}
+
+	app.logLevel.Set(level)
+
+	app.closeLogFiles()
+
+	var writers []io.Writer
+
+	switch app.baseSettings.LogOutput {
+	case "stderr", "":
+		writers = append(writers, os.Stderr)
+	case "file", "both":
+		if app.baseSettings.LogFile == "" {
+			return errors.New("log_file required for log_output=" + app.baseSettings.LogOutput)
+		}
+
+		if app.logFile, err = app.openLogFile(app.baseSettings.LogFile); err != nil {
+			return err
+		}
+
+		writers = append(writers, app.logFile)
+
+		if app.baseSettings.LogOutput == "both" {
+			writers = append(writers, os.Stderr)
+		}
+	default:
+		return fmt.Errorf("unknown log_output '%s'", app.baseSettings.LogOutput)
+	}
+
+	if err := app.initAccessLog(); err != nil {
+		return err
+	}
+
+	if app.logFile != nil || app.accessLogFile != nil {
+		app.logReopenOnce.Do(app.watchLogReopenSignal)
+	}
+
+	handler, err := newLogHandler(app.baseSettings.LogFormat, io.MultiWriter(writers...), &app.logLevel)
+	if err != nil {
+		return err
+	}
+
+	app.setLogger(slog.New(handler))
+
+	return nil
+}
+
+func (app *AppBase) setLogger(logger *slog.Logger) {
+	//request_id attribute for records logged with request context
+	if _, ok := logger.Handler().(*requestIdLogHandler); !ok {
+		logger = slog.New(&requestIdLogHandler{logger.Handler()})
+	}
+
+	app.logger = logger
+}
+
+func (app *AppBase) openLogFile(path string) (*LogFile, error) {
+	return OpenLogFile(
+		path,
+		int64(app.baseSettings.LogFileMaxSize)*1024*1024,
+		time.Duration(app.baseSettings.LogFileMaxAge)*time.Hour,
+		app.baseSettings.LogFileMaxBackups,
+	)
+}
+
+func (app *AppBase) closeLogFiles() {
+	if app.logFile != nil {
+		app.logFile.Close()
+		app.logFile = nil
+	}
+
+	if app.accessLogFile != nil {
+		app.accessLogFile.Close()
+		app.accessLogFile = nil
+	}
+}
+
+// Reopens log files on SIGHUP so external logrotate can move them away.
+func (app *AppBase) watchLogReopenSignal() {
+	hupChannel := make(chan os.Signal, 1)
+	signal.Notify(hupChannel, syscall.SIGHUP)
+
+	go func() {
+		for range hupChannel {
+			app.reopenLogFiles()
+		}
+	}()
+}
+
+// Reopens log files (on SIGHUP or `ctl reload`). Returns last error.
+func (app *AppBase) reopenLogFiles() (err error) {
+	if app.logFile == nil && app.accessLogFile == nil {
+		return nil
+	}
+
+	for _, lf := range []*LogFile{app.logFile, app.accessLogFile} {
+		if lf == nil {
+			continue
+		}
+
+		if reopenErr := lf.Reopen(); reopenErr != nil {
+			err = reopenErr
+			fmt.Fprintf(os.Stderr, "log file %s reopen failed: %s\n", lf.Path, err)
+		}
+	}
+
+	app.logger.Info("Log files reopened")
+
+	return err
+}
+
+func newLogHandler(format string, w io.Writer, level slog.Leveler) (slog.Handler, error) {
+	options := &slog.HandlerOptions{Level: level}
+
+	switch format {
+	case "text", "":
+		return slog.NewTextHandler(w, options), nil
+	case "json":
+		return slog.NewJSONHandler(w, options), nil
+	default:
+		return nil, fmt.Errorf("unknown log_format '%s'", format)
+	}
+}
+
+// io.Writer sending every written line to slog logger.
+type slogWriter struct {
+	logger *slog.Logger
+	level  slog.Level
+}
+
+func (w *slogWriter) Write(p []byte) (n int, err error) {
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			w.logger.Log(context.Background(), w.level, line)
+		}
+	}
+
+	return len(p), nil
+}
+
+// gorm logger.Interface implementation using slog.
+type gormSlogLogger struct {
+	logger        *slog.Logger
+	slowThreshold time.Duration
+}
+
+func (l *gormSlogLogger) LogMode(gormlogger.LogLevel) gormlogger.Interface {
+	return l //level is controlled by slog
+}
+
+func (l *gormSlogLogger) Info(ctx context.Context, msg string, args ...any) {
+	l.logger.InfoContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+func (l *gormSlogLogger) Warn(ctx context.Context, msg string, args ...any) {
+	l.logger.WarnContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+func (l *gormSlogLogger) Error(ctx context.Context, msg string, args ...any) {
+	l.logger.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+func (l *gormSlogLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	elapsed := time.Since(begin)
+	logger := l.logger
+
+	switch {
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold:
+		sql, rows := fc()
+		logger.WarnContext(ctx, "Slow query", "sql", sql, "rows", rows, "elapsed", elapsed, "error", err)
+	case logger.Enabled(ctx, slog.LevelDebug):
+		//errors are logged by query helpers or handled by callers, so just trace them here
+		sql, rows := fc()
+		logger.DebugContext(ctx, "Query", "sql", sql, "rows", rows, "elapsed", elapsed, "error", err)
+	}
+}
@@ -0,0 +1,160 @@
+package goapp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseLogLevel(t *testing.T) {
+	for name, expected := range map[string]slog.Level{
+		"debug": slog.LevelDebug,
+		"info":  slog.LevelInfo,
+		"WARN":  slog.LevelWarn,
+		"error": slog.LevelError,
+	} {
+		if level, err := ParseLogLevel(name); err != nil || level != expected {
+			t.Errorf("ParseLogLevel(%s) = %v, %v", name, level, err)
+		}
+	}
+
+	if _, err := ParseLogLevel("verbose"); err == nil {
+		t.Error("unknown level should fail")
+	}
+}
+
+func TestLoggerFormats(t *testing.T) {
+	for _, format := range []string{"text", "json"} {
+		app := NewAppBase(&testAppSettings{})
+		app.baseSettings.LogFormat = format
+		app.baseSettings.LogOutput = "file"
+		app.baseSettings.LogFile = filepath.Join(t.TempDir(), "app.log")
+		app.baseSettings.LogLevel = "warn"
+
+		if err := app.initLogger(); err != nil {
+			t.Fatal(err)
+		}
+
+		app.Logger().Info("hidden message")
+		app.componentLogger(LogComponentDb).Warn("visible message", "key", "value")
+		app.closeLogFiles()
+
+		data, err := os.ReadFile(app.baseSettings.LogFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if strings.Contains(string(data), "hidden message") {
+			t.Errorf("%s: info message logged with warn level", format)
+		}
+
+		switch format {
+		case "text":
+			if !strings.Contains(string(data), `msg="visible message" component=db key=value`) {
+				t.Errorf("unexpected text log: %s", data)
+			}
+		case "json":
+			var record map[string]any
+			if err := json.Unmarshal(data, &record); err != nil || record["msg"] != "visible message" || record["component"] != "db" {
+				t.Errorf("unexpected json log: %s (%v)", data, err)
+			}
+		}
+	}
+
+	app := NewAppBase(&testAppSettings{})
+	app.baseSettings.LogFormat = "xml"
+
+	if err := app.initLogger(); err == nil {
+		t.Error("unknown log format should fail")
+	}
+
+	app.baseSettings.LogFormat = "text"
+	app.baseSettings.LogOutput = "file"
+
+	if err := app.initLogger(); err == nil {
+		t.Error("log_output=file without log_file should fail")
+	}
+}
+
+func TestLoggerOutputBoth(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stderr := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = stderr }()
+
+	app := NewAppBase(&testAppSettings{})
+	app.baseSettings.LogOutput = "both"
+	app.baseSettings.LogFile = filepath.Join(t.TempDir(), "app.log")
+
+	err = app.initLogger()
+	os.Stderr = stderr
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app.Logger().Info("both outputs")
+	app.closeLogFiles()
+	w.Close()
+
+	stderrData, _ := io.ReadAll(r)
+	fileData, _ := os.ReadFile(app.baseSettings.LogFile)
+
+	if !strings.Contains(string(stderrData), "both outputs") || !strings.Contains(string(fileData), "both outputs") {
+		t.Errorf("message should be written to stderr (%q) and file (%q)", stderrData, fileData)
+	}
+}
+
+func TestLoggerPerApp(t *testing.T) {
+	var buffer1, buffer2 bytes.Buffer
+
+	app1 := NewAppBase(&testAppSettings{})
+	app1.setLogger(slog.New(slog.NewTextHandler(&buffer1, nil)))
+
+	app2 := NewAppBase(&testAppSettings{})
+	app2.setLogger(slog.New(slog.NewTextHandler(&buffer2, nil)))
+
+	app1.componentLogger(LogComponentApp).Info("first app")
+	app2.componentLogger(LogComponentApp).Info("second app")
+
+	if strings.Contains(buffer1.String(), "second app") || !strings.Contains(buffer1.String(), "first app") {
+		t.Errorf("unexpected first app log: %s", buffer1.String())
+	}
+
+	if strings.Contains(buffer2.String(), "first app") || !strings.Contains(buffer2.String(), "second app") {
+		t.Errorf("unexpected second app log: %s", buffer2.String())
+	}
+
+	if slog.Default().Handler() == app2.logger.Handler() {
+		t.Error("app logger should not become slog default")
+	}
+}
+
+func TestGinWriters(t *testing.T) {
+	var buffer bytes.Buffer
+
+	app := NewAppBase(&testAppSettings{})
+	app.setLogger(slog.New(slog.NewTextHandler(&buffer, nil)))
+
+	defaultWriter, defaultErrorWriter := gin.DefaultWriter, gin.DefaultErrorWriter
+	defer func() { gin.DefaultWriter, gin.DefaultErrorWriter = defaultWriter, defaultErrorWriter }()
+
+	app.setGinWriters()
+	fmt.Fprintln(gin.DefaultErrorWriter, "gin warning")
+
+	if !strings.Contains(buffer.String(), `level=ERROR msg="gin warning" component=web`) {
+		t.Errorf("gin output should be sent to application log: %s", buffer.String())
+	}
+}
@@ -0,0 +1,141 @@
+package goapp
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// how often maintenance flag file is checked by running instance
+const maintenanceCheckInterval = time.Second
+
+// Retry-After value if state has no one
+const defaultMaintenanceRetryAfter = 300
+
+// Maintenance mode state, stored in flag file as JSON. Empty flag file (created with `touch`)
+// turns maintenance mode on with default message.
+type MaintenanceState struct {
+	Message    string    `json:"message,omitempty"`     // text shown to users
+	RetryAfter int       `json:"retry_after,omitempty"` // seconds, Retry-After header value
+	Since      time.Time `json:"since"`
+}
+
+// Cached flag file state
+type maintenanceCache struct {
+	mutex     sync.Mutex
+	state     *MaintenanceState
+	checkedAt time.Time
+}
+
+// Returns maintenance flag file path: maintenance_file setting or ".maintenance" next to settings file.
+func (app *AppBase) MaintenanceFile() string {
+	if app.baseSettings.MaintenanceFile != "" {
+		return app.baseSettings.MaintenanceFile
+	}
+
+	return filepath.Join(filepath.Dir(app.AppSettingsFilename), ".maintenance")
+}
+
+// Turns maintenance mode on (state is not nil) or off (state is nil). Flag file is written or
+// removed, so all running instances pick it up within a second.
+func (app *AppBase) SetMaintenance(state *MaintenanceState) error {
+	file := app.MaintenanceFile()
+
+	if state == nil {
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		app.logger.Info("Maintenance mode is off", "component", LogComponentApp)
+	} else {
+		s := *state
+
+		if s.Since.IsZero() {
+			s.Since = time.Now()
+		}
+
+		if s.RetryAfter <= 0 {
+			s.RetryAfter = defaultMaintenanceRetryAfter
+		}
+
+		data, err := json.MarshalIndent(&s, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		// write and rename, so running instances never read half-written file
+		if err := os.WriteFile(file+".tmp", data, 0644); err != nil {
+			return err
+		}
+
+		if err := os.Rename(file+".tmp", file); err != nil {
+			return err
+		}
+
+		app.logger.Info("Maintenance mode is on", "component", LogComponentApp, "message", s.Message)
+	}
+
+	// re-read on next check
+	app.maintenance.mutex.Lock()
+	app.maintenance.checkedAt = time.Time{}
+	app.maintenance.mutex.Unlock()
+
+	return nil
+}
+
+// Returns maintenance mode state or nil if it is off.
+func (app *AppBase) Maintenance() *MaintenanceState {
+	m := &app.maintenance
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if time.Since(m.checkedAt) < maintenanceCheckInterval {
+		return m.state
+	}
+
+	state, err := readMaintenanceFile(app.MaintenanceFile())
+	if err != nil {
+		app.logger.Warn("Can not read maintenance flag file, maintenance mode is on", "component", LogComponentApp, "error", err)
+	}
+
+	m.state = state
+	m.checkedAt = time.Now()
+
+	return m.state
+}
+
+// Returns nil if there is no flag file. Unreadable or invalid file still means maintenance mode
+// is on (state is returned along with error).
+func readMaintenanceFile(file string) (*MaintenanceState, error) {
+	info, err := os.Stat(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	state := &MaintenanceState{RetryAfter: defaultMaintenanceRetryAfter}
+
+	if err != nil {
+		return state, err
+	}
+
+	state.Since = info.ModTime()
+
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil // removed just now
+	}
+
+	if err != nil || len(data) == 0 {
+		return state, err
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return state, err
+	}
+
+	return state, nil
+}
@@ -0,0 +1,41 @@
+package goapp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMaintenanceFile(t *testing.T) {
+	app := NewAppBase(&testAppSettings{})
+	app.AppSettingsFilename = filepath.Join(t.TempDir(), "settings.yml")
+
+	if app.Maintenance() != nil {
+		t.Fatal("maintenance mode should be off")
+	}
+
+	if err := app.SetMaintenance(&MaintenanceState{Message: "Upgrading"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if state := app.Maintenance(); state == nil || state.Message != "Upgrading" || state.RetryAfter != defaultMaintenanceRetryAfter || state.Since.IsZero() {
+		t.Errorf("unexpected state: %+v", state)
+	}
+
+	if err := app.SetMaintenance(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if app.Maintenance() != nil {
+		t.Error("maintenance mode should be off")
+	}
+
+	// empty flag file created by other tools
+	if err := os.WriteFile(app.MaintenanceFile(), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if state, err := readMaintenanceFile(app.MaintenanceFile()); err != nil || state == nil || state.Since.IsZero() {
+		t.Errorf("empty flag file should turn maintenance mode on: %+v %v", state, err)
+	}
+}
@@ -0,0 +1,385 @@
+package goapp
+
+import (
+	"bufio"
+	"io"
+	"log"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Default histogram buckets (seconds) for request durations.
+var DefaultDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+var metricNameRe = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+// Metrics registry exposed in Prometheus text format, see AppBase.Metrics().
+type MetricsRegistry struct {
+	mutex    sync.Mutex
+	families []metricFamily
+	names    map[string]bool
+}
+
+// Metric family (or several ones) writing itself in exposition format.
+type metricFamily interface {
+	write(w *bufio.Writer)
+}
+
+func newMetricsRegistry() *MetricsRegistry {
+	return &MetricsRegistry{names: make(map[string]bool)}
+}
+
+func (r *MetricsRegistry) register(f metricFamily, names ...string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, name := range names {
+		if !metricNameRe.MatchString(name) {
+			log.Panicf("invalid metric name '%s'", name)
+		}
+
+		if r.names[name] {
+			log.Panicf("metric '%s' already registered", name)
+		}
+
+		r.names[name] = true
+	}
+
+	r.families = append(r.families, f)
+}
+
+// Registers counter with given label names.
+func (r *MetricsRegistry) Counter(name, help string, labelNames ...string) *Counter {
+	v := newMetricVec(name, help, "counter", labelNames)
+	r.register(v, name)
+
+	return &Counter{vec: v}
+}
+
+// Registers gauge with given label names.
+func (r *MetricsRegistry) Gauge(name, help string, labelNames ...string) *Gauge {
+	v := newMetricVec(name, help, "gauge", labelNames)
+	r.register(v, name)
+
+	return &Gauge{vec: v}
+}
+
+// Registers histogram with given label names. Buckets are upper bounds, DefaultDurationBuckets
+// are used if nil.
+func (r *MetricsRegistry) Histogram(name, help string, buckets []float64, labelNames ...string) *Histogram {
+	if buckets == nil {
+		buckets = DefaultDurationBuckets
+	}
+
+	h := &Histogram{
+		metricVec: *newMetricVec(name, help, "histogram", labelNames),
+		buckets:   slices.Sorted(slices.Values(buckets)),
+		series:    make(map[string]*histogramSeries),
+	}
+	r.register(h, name)
+
+	return h
+}
+
+// Registers gauge with value returned by f on every scrape.
+func (r *MetricsRegistry) GaugeFunc(name, help string, f func() float64) {
+	r.register(newMetricCollector(name, help, "gauge", nil, func() []metricSample {
+		return []metricSample{{value: f()}}
+	}), name)
+}
+
+// Registers counter with value returned by f on every scrape.
+func (r *MetricsRegistry) CounterFunc(name, help string, f func() float64) {
+	r.register(newMetricCollector(name, help, "counter", nil, func() []metricSample {
+		return []metricSample{{value: f()}}
+	}), name)
+}
+
+// Writes all metrics in Prometheus text format.
+func (r *MetricsRegistry) WriteTo(w io.Writer) (int64, error) {
+	r.mutex.Lock()
+	families := slices.Clone(r.families)
+	r.mutex.Unlock()
+
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+
+	for _, f := range families {
+		f.write(bw)
+	}
+
+	err := bw.Flush()
+
+	return cw.n, err
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+
+	return n, err
+}
+
+// Name, help and labels shared by all metric kinds.
+type metricDesc struct {
+	name       string
+	help       string
+	kind       string // counter, gauge or histogram
+	labelNames []string
+}
+
+type metricSample struct {
+	labelValues []string
+	value       float64
+}
+
+// Metric with value per labels set.
+type metricVec struct {
+	metricDesc
+
+	mutex  sync.Mutex
+	values map[string]*metricSample
+}
+
+func newMetricVec(name, help, kind string, labelNames []string) *metricVec {
+	return &metricVec{
+		metricDesc: metricDesc{name: name, help: help, kind: kind, labelNames: labelNames},
+		values:     make(map[string]*metricSample),
+	}
+}
+
+func (v *metricVec) labelsKey(labelValues []string) string {
+	if len(labelValues) != len(v.labelNames) {
+		log.Panicf("metric '%s' expects %d label values, got %d", v.name, len(v.labelNames), len(labelValues))
+	}
+
+	return strings.Join(labelValues, "\xff")
+}
+
+func (v *metricVec) update(labelValues []string, f func(value float64) float64) {
+	key := v.labelsKey(labelValues)
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	sample, ok := v.values[key]
+	if !ok {
+		sample = &metricSample{labelValues: slices.Clone(labelValues)}
+		v.values[key] = sample
+	}
+
+	sample.value = f(sample.value)
+}
+
+func (v *metricVec) write(w *bufio.Writer) {
+	v.mutex.Lock()
+	samples := make([]metricSample, 0, len(v.values))
+	for _, key := range sortedKeys(v.values) {
+		samples = append(samples, *v.values[key])
+	}
+	v.mutex.Unlock()
+
+	v.metricDesc.write(w, samples)
+}
+
+func (d *metricDesc) write(w *bufio.Writer, samples []metricSample) {
+	if len(samples) == 0 {
+		return
+	}
+
+	writeMetricHeader(w, d.name, d.help, d.kind)
+
+	for _, s := range samples {
+		writeMetricSample(w, d.name, d.labelNames, s.labelValues, s.value)
+	}
+}
+
+// Counter metric. Label values are passed to methods in order of label names.
+type Counter struct {
+	vec *metricVec
+}
+
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Adds v (should not be negative).
+func (c *Counter) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		log.Panicf("counter '%s' can not decrease", c.vec.name)
+	}
+
+	c.vec.update(labelValues, func(value float64) float64 { return value + v })
+}
+
+// Gauge metric. Label values are passed to methods in order of label names.
+type Gauge struct {
+	vec *metricVec
+}
+
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	g.vec.update(labelValues, func(float64) float64 { return v })
+}
+
+func (g *Gauge) Add(v float64, labelValues ...string) {
+	g.vec.update(labelValues, func(value float64) float64 { return value + v })
+}
+
+func (g *Gauge) Inc(labelValues ...string) {
+	g.Add(1, labelValues...)
+}
+
+func (g *Gauge) Dec(labelValues ...string) {
+	g.Add(-1, labelValues...)
+}
+
+// Histogram metric. Label values are passed to Observe() in order of label names.
+type Histogram struct {
+	metricVec
+
+	buckets []float64
+	series  map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	labelValues []string
+	counts      []uint64 // per bucket, not cumulative
+	count       uint64
+	sum         float64
+}
+
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	key := h.labelsKey(labelValues)
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{labelValues: slices.Clone(labelValues), counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+
+	if i, _ := slices.BinarySearch(h.buckets, v); i < len(h.buckets) {
+		s.counts[i]++
+	}
+
+	s.count++
+	s.sum += v
+}
+
+func (h *Histogram) write(w *bufio.Writer) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if len(h.series) == 0 {
+		return
+	}
+
+	writeMetricHeader(w, h.name, h.help, h.kind)
+
+	labelNames := append(slices.Clone(h.labelNames), "le")
+
+	for _, key := range sortedKeys(h.series) {
+		s := h.series[key]
+
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += s.counts[i]
+			writeMetricSample(w, h.name+"_bucket", labelNames, append(slices.Clone(s.labelValues), formatMetricValue(bound)), float64(cumulative))
+		}
+
+		writeMetricSample(w, h.name+"_bucket", labelNames, append(slices.Clone(s.labelValues), "+Inf"), float64(s.count))
+		writeMetricSample(w, h.name+"_sum", h.labelNames, s.labelValues, s.sum)
+		writeMetricSample(w, h.name+"_count", h.labelNames, s.labelValues, float64(s.count))
+	}
+}
+
+// Metric with samples collected on every scrape.
+type metricCollector struct {
+	metricDesc
+
+	collect func() []metricSample
+}
+
+func newMetricCollector(name, help, kind string, labelNames []string, collect func() []metricSample) *metricCollector {
+	return &metricCollector{
+		metricDesc: metricDesc{name: name, help: help, kind: kind, labelNames: labelNames},
+		collect:    collect,
+	}
+}
+
+func (c *metricCollector) write(w *bufio.Writer) {
+	c.metricDesc.write(w, c.collect())
+}
+
+// Several metric families collected together (like ones from single runtime.ReadMemStats() call).
+type metricCollectorGroup struct {
+	collect func() []*metricCollector
+}
+
+func (g *metricCollectorGroup) write(w *bufio.Writer) {
+	for _, c := range g.collect() {
+		c.write(w)
+	}
+}
+
+func writeMetricHeader(w *bufio.Writer, name, help, kind string) {
+	w.WriteString("# HELP " + name + " " + strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help) + "\n")
+	w.WriteString("# TYPE " + name + " " + kind + "\n")
+}
+
+var metricLabelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func writeMetricSample(w *bufio.Writer, name string, labelNames, labelValues []string, value float64) {
+	w.WriteString(name)
+
+	if len(labelNames) > 0 {
+		w.WriteByte('{')
+
+		for i, labelName := range labelNames {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+
+			w.WriteString(labelName + `="` + metricLabelValueReplacer.Replace(labelValues[i]) + `"`)
+		}
+
+		w.WriteByte('}')
+	}
+
+	w.WriteString(" " + formatMetricValue(value) + "\n")
+}
+
+func formatMetricValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
@@ -0,0 +1,77 @@
+package goapp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMetricsRegistry(t *testing.T) {
+	r := newMetricsRegistry()
+
+	counter := r.Counter("test_orders_total", "Orders placed", "payment")
+	counter.Inc("card")
+	counter.Add(2, "card")
+	counter.Inc(`cash "eur"`)
+
+	gauge := r.Gauge("test_queue", "Queue\nlength")
+	gauge.Set(5)
+	gauge.Dec()
+
+	histogram := r.Histogram("test_duration_seconds", "Durations", []float64{1, 0.1}, "op")
+	histogram.Observe(0.05, "read")
+	histogram.Observe(0.5, "read")
+	histogram.Observe(3, "read")
+
+	r.GaugeFunc("test_answer", "Answer", func() float64 { return 42 })
+	r.Counter("test_unused_total", "Not used yet")
+
+	var sb strings.Builder
+	if _, err := r.WriteTo(&sb); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `# HELP test_orders_total Orders placed
+# TYPE test_orders_total counter
+test_orders_total{payment="card"} 3
+test_orders_total{payment="cash \"eur\""} 1
+# HELP test_queue Queue\nlength
+# TYPE test_queue gauge
+test_queue 4
+# HELP test_duration_seconds Durations
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{op="read",le="0.1"} 1
+test_duration_seconds_bucket{op="read",le="1"} 2
+test_duration_seconds_bucket{op="read",le="+Inf"} 3
+test_duration_seconds_sum{op="read"} 3.55
+test_duration_seconds_count{op="read"} 3
+# HELP test_answer Answer
+# TYPE test_answer gauge
+test_answer 42
+`
+
+	if sb.String() != expected {
+		t.Errorf("unexpected output:\n%s", sb.String())
+	}
+}
+
+func TestMetricsRegistryPanics(t *testing.T) {
+	r := newMetricsRegistry()
+	counter := r.Counter("test_total", "Test", "label")
+
+	for name, f := range map[string]func(){
+		"duplicate name":     func() { r.Gauge("test_total", "Test") },
+		"invalid name":       func() { r.Gauge("test-gauge", "Test") },
+		"wrong label values": func() { counter.Inc() },
+		"negative counter":   func() { counter.Add(-1, "x") },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: panic expected", name)
+				}
+			}()
+
+			f()
+		}()
+	}
+}
@@ -0,0 +1,198 @@
+package goapp
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Reusable application feature (users system, blog etc) packaged as drop-in unit.
+// Embed AppModuleBase to implement only methods module needs.
+type AppModule interface {
+	ModuleName() string // unique module name, also used as YAML key for module settings section
+
+	Commands() []*cobra.Command                // CLI subcommands
+	BuildWebRouter(r *gin.Engine)              // web routes, called before AppBase.BuildWebRouterF
+	ApiHandlers() map[string]ApiRequestHandler // web API handlers: path => handler
+	Models() []reflect.Type                    // model types for AppBase.Database schema
+	Settings() any                             // pointer to module settings struct or nil
+	PrintInfo()                                // prints additional information for `info` subcommand
+	Init() error                               // additional code for `init` subcommand
+	Components() []Component                   // lifecycle hooks
+}
+
+// AppModule implementation with empty methods. Embed it to module struct.
+type AppModuleBase struct{}
+
+func (m *AppModuleBase) Commands() []*cobra.Command                { return nil }
+func (m *AppModuleBase) BuildWebRouter(r *gin.Engine)              {}
+func (m *AppModuleBase) ApiHandlers() map[string]ApiRequestHandler { return nil }
+func (m *AppModuleBase) Models() []reflect.Type                    { return nil }
+func (m *AppModuleBase) Settings() any                             { return nil }
+func (m *AppModuleBase) PrintInfo()                                {}
+func (m *AppModuleBase) Init() error                               { return nil }
+func (m *AppModuleBase) Components() []Component                   { return nil }
+
+// Wires module to application: registers its API handlers, models and components right away.
+// Commands, routes, settings, info output and init code are wired in corresponding places later.
+// Panics if module conflicts with already registered ones.
+func (app *AppBase) UseModule(m AppModule) *AppBase {
+	name := m.ModuleName()
+
+	if name == "" {
+		log.Panicln("module name should not be empty")
+	}
+
+	if app.Module(name) != nil {
+		log.Panicf("module %s already registered", name)
+	}
+
+	if m.Settings() != nil && slices.Contains(yamlKeys(app.AppSettings), name) {
+		log.Panicf("module %s settings key conflicts with application setting of the same name", name)
+	}
+
+	for path := range m.ApiHandlers() {
+		if _, exists := app.webApiHandlerList[path]; exists {
+			log.Panicf("module %s: API handler for path '%s' already registered", name, path)
+		}
+	}
+
+	for _, modelType := range m.Models() {
+		if app.Database.HasModel(modelType) {
+			log.Panicf("module %s: model %s already registered", name, modelType.String())
+		}
+	}
+
+	//no conflicts found, wire everything
+	for path, handler := range m.ApiHandlers() {
+		app.ApiHandler(path, handler)
+	}
+
+	for _, modelType := range m.Models() {
+		app.Database.AddModel(modelType)
+	}
+
+	for _, c := range m.Components() {
+		app.AddComponent(c)
+	}
+
+	app.modules = append(app.modules, m)
+
+	return app //for method chaining
+}
+
+// Returns registered module by name or nil if there is no such module.
+func (app *AppBase) Module(name string) AppModule {
+	for _, m := range app.modules {
+		if m.ModuleName() == name {
+			return m
+		}
+	}
+
+	return nil
+}
+
+// Adds modules subcommands to root command. Panics on command name conflicts.
+func (app *AppBase) addModulesCommands() {
+	for _, m := range app.modules {
+		for _, cmd := range m.Commands() {
+			for _, existing := range app.rootCmd.Commands() {
+				if existing.Name() == cmd.Name() {
+					log.Panicf("module %s: command '%s' already exists", m.ModuleName(), cmd.Name())
+				}
+			}
+
+			app.rootCmd.AddCommand(cmd)
+		}
+	}
+}
+
+// Loads modules settings from their sections of settings file.
+func (app *AppBase) loadModulesSettings() error {
+	if !app.hasModulesSettings() {
+		return nil
+	}
+
+	data, err := os.ReadFile(app.AppSettingsFilename)
+	if err != nil {
+		return err
+	}
+
+	var sections map[string]yaml.Node
+
+	if err := yaml.Unmarshal(data, &sections); err != nil {
+		return err
+	}
+
+	for _, m := range app.modules {
+		if m.Settings() == nil {
+			continue
+		}
+
+		if node, ok := sections[m.ModuleName()]; ok {
+			if err := node.Decode(m.Settings()); err != nil {
+				return fmt.Errorf("module %s settings: %w", m.ModuleName(), err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Returns modules settings as YAML document (module name => settings section).
+func (app *AppBase) modulesSettingsYaml() ([]byte, error) {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+
+	for _, m := range app.modules {
+		if m.Settings() == nil {
+			continue
+		}
+
+		valueNode := &yaml.Node{}
+		if err := valueNode.Encode(m.Settings()); err != nil {
+			return nil, err
+		}
+
+		root.Content = append(root.Content,
+			&yaml.Node{
+				Kind:        yaml.ScalarNode,
+				Value:       m.ModuleName(),
+				HeadComment: "Module " + m.ModuleName() + " settings",
+			},
+			valueNode,
+		)
+	}
+
+	return yaml.Marshal(root)
+}
+
+func (app *AppBase) hasModulesSettings() bool {
+	for _, m := range app.modules {
+		if m.Settings() != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns top level YAML keys of value.
+func yamlKeys(value any) (keys []string) {
+	node := &yaml.Node{}
+
+	if err := node.Encode(value); err != nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i < len(node.Content); i += 2 {
+		keys = append(keys, node.Content[i].Value)
+	}
+
+	return keys
+}
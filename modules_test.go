@@ -0,0 +1,74 @@
+package goapp
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+type testModuleSettings struct {
+	Greeting string `yaml:"greeting"`
+}
+
+type testModule struct {
+	AppModuleBase
+
+	name     string
+	settings *testModuleSettings
+}
+
+func (m *testModule) ModuleName() string { return m.name }
+func (m *testModule) Settings() any      { return m.settings }
+func (m *testModule) ApiHandlers() map[string]ApiRequestHandler {
+	return map[string]ApiRequestHandler{"/" + m.name: func(r *ApiRequest) error { return nil }}
+}
+
+type testAppSettings struct {
+	AppSettingsBase `yaml:",inline"`
+}
+
+func TestModuleSettings(t *testing.T) {
+	app := NewAppBase(&testAppSettings{})
+	app.AppSettingsFilename = filepath.Join(t.TempDir(), "settings.yml")
+
+	m := &testModule{name: "greeter", settings: &testModuleSettings{Greeting: "hello"}}
+	app.UseModule(m)
+
+	if err := app.SaveSettings("test"); err != nil {
+		t.Fatal(err)
+	}
+
+	m.settings.Greeting = ""
+
+	if err := app.loadSettings(); err != nil {
+		t.Fatal(err)
+	}
+
+	if m.settings.Greeting != "hello" {
+		t.Errorf("module settings not loaded, got '%s'", m.settings.Greeting)
+	}
+}
+
+func TestModuleConflicts(t *testing.T) {
+	app := NewAppBase(&testAppSettings{})
+	app.UseModule(&testModule{name: "one"})
+
+	assertPanics := func(name string, f func()) {
+		t.Helper()
+
+		defer func() {
+			if recover() == nil {
+				t.Errorf("%s: panic expected", name)
+			}
+		}()
+
+		f()
+	}
+
+	assertPanics("same module name", func() { app.UseModule(&testModule{name: "one"}) })
+	assertPanics("settings key conflict", func() {
+		app.UseModule(&testModule{name: "production", settings: &testModuleSettings{}})
+	})
+
+	app.ApiHandler("/two", func(r *ApiRequest) error { return nil })
+	assertPanics("API path conflict", func() { app.UseModule(&testModule{name: "two"}) })
+}
@@ -0,0 +1,221 @@
+package goapp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// gin context key with API handler path (without WebApiPathPrefix)
+const apiPathGinKey = "goapp.api_path"
+
+// Access log record
+type accessLogEntry struct {
+	Time      time.Time `json:"time"`
+	ClientIp  string    `json:"client_ip"`
+	UserId    string    `json:"user_id,omitempty"`
+	Method    string    `json:"method"`
+	Uri       string    `json:"uri"`
+	Proto     string    `json:"proto"`
+	Status    int       `json:"status"`
+	Bytes     int       `json:"bytes"`
+	LatencyMs float64   `json:"latency_ms"`
+	Referer   string    `json:"referer,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	RequestId string    `json:"request_id,omitempty"`
+	ApiPath   string    `json:"api_path,omitempty"`
+}
+
+// Opens access log output from settings. Called from initLogger().
+func (app *AppBase) initAccessLog() (err error) {
+	app.accessLog = nil
+	app.accessLogToAppLog = false
+
+	switch app.baseSettings.AccessLogFormat {
+	case "common", "combined", "json", "":
+	default:
+		return fmt.Errorf("unknown access_log_format '%s'", app.baseSettings.AccessLogFormat)
+	}
+
+	output := app.baseSettings.AccessLog
+
+	if output == "" && app.baseSettings.AccessLogFile != "" {
+		output = "file" // access_log_file only was used before access_log setting appeared
+	}
+
+	if app.WebRouterLogQueries {
+		output = "log"
+	}
+
+	switch output {
+	case "":
+		// no access log
+	case "file":
+		if app.baseSettings.AccessLogFile == "" {
+			return fmt.Errorf("access_log_file required for access_log=file")
+		}
+
+		if app.accessLogFile, err = app.openLogFile(app.baseSettings.AccessLogFile); err != nil {
+			return err
+		}
+
+		app.accessLog = app.accessLogFile
+	case "stdout":
+		app.accessLog = os.Stdout
+	case "log":
+		app.accessLogToAppLog = true
+	default:
+		return fmt.Errorf("unknown access_log '%s'", output)
+	}
+
+	return nil
+}
+
+// gin middleware writing every request to access log.
+func (app *AppBase) accessLogMiddleware(c *gin.Context) {
+	start := time.Now()
+
+	c.Next()
+
+	status := c.Writer.Status()
+
+	if app.isAccessLogExcluded(c.Request.URL.Path) {
+		return
+	}
+
+	// sampling, server errors are always logged
+	if status < 500 && app.baseSettings.AccessLogSampleRate < 1 && rand.Float64() >= app.baseSettings.AccessLogSampleRate {
+		return
+	}
+
+	entry := &accessLogEntry{
+		Time:      start,
+		ClientIp:  c.ClientIP(),
+		UserId:    app.currentUserId(c),
+		Method:    c.Request.Method,
+		Uri:       c.Request.URL.RequestURI(),
+		Proto:     c.Request.Proto,
+		Status:    status,
+		Bytes:     max(c.Writer.Size(), 0),
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+		Referer:   c.Request.Referer(),
+		UserAgent: c.Request.UserAgent(),
+		RequestId: RequestId(c),
+		ApiPath:   c.GetString(apiPathGinKey),
+	}
+
+	if app.accessLogToAppLog {
+		app.logAccessEntry(c, entry)
+		return
+	}
+
+	app.accessLog.Write(formatAccessLogEntry(entry, app.baseSettings.AccessLogFormat))
+}
+
+// Writes access log entry to application log.
+func (app *AppBase) logAccessEntry(c *gin.Context, entry *accessLogEntry) {
+	level := slog.LevelInfo
+	if entry.Status >= 500 {
+		level = slog.LevelError
+	}
+
+	args := []any{
+		"component", LogComponentWeb,
+		"method", entry.Method,
+		"path", c.Request.URL.Path,
+		"status", entry.Status,
+		"latency_ms", entry.LatencyMs,
+		"client_ip", entry.ClientIp,
+		"bytes", entry.Bytes,
+	}
+
+	if entry.UserId != "" {
+		args = append(args, "user_id", entry.UserId)
+	}
+
+	if entry.ApiPath != "" {
+		args = append(args, "api_path", entry.ApiPath)
+	}
+
+	app.logger.Log(c.Request.Context(), level, "HTTP request", args...)
+}
+
+// Formats entry as Apache common or combined log line (with latency, request ID and API path
+// appended) or JSON line.
+func formatAccessLogEntry(entry *accessLogEntry, format string) []byte {
+	if format == "json" {
+		line, _ := json.Marshal(entry)
+		return append(line, '\n')
+	}
+
+	var b bytes.Buffer
+
+	bytesField := "-"
+	if entry.Bytes > 0 {
+		bytesField = strconv.Itoa(entry.Bytes)
+	}
+
+	fmt.Fprintf(&b, "%s - %s [%s] \"%s %s %s\" %d %s",
+		entry.ClientIp,
+		dashIfEmpty(entry.UserId),
+		entry.Time.Format("02/Jan/2006:15:04:05 -0700"),
+		entry.Method,
+		entry.Uri,
+		entry.Proto,
+		entry.Status,
+		bytesField,
+	)
+
+	if format == "combined" || format == "" {
+		fmt.Fprintf(&b, " %s %s", strconv.Quote(dashIfEmpty(entry.Referer)), strconv.Quote(dashIfEmpty(entry.UserAgent)))
+	}
+
+	fmt.Fprintf(&b, " rt=%.3f rid=%s", entry.LatencyMs/1000, dashIfEmpty(entry.RequestId))
+
+	if entry.ApiPath != "" {
+		fmt.Fprintf(&b, " api=%s", entry.ApiPath)
+	}
+
+	b.WriteByte('\n')
+
+	return b.Bytes()
+}
+
+func dashIfEmpty(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
+
+func (app *AppBase) isAccessLogExcluded(path string) bool {
+	for _, excluded := range app.baseSettings.AccessLogExcludePaths {
+		if prefix, found := strings.CutSuffix(excluded, "*"); found {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		} else if path == excluded {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns current user ID using CurrentUserIdF. Empty string for anonymous users.
+func (app *AppBase) currentUserId(c *gin.Context) string {
+	if app.CurrentUserIdF == nil {
+		return ""
+	}
+
+	return app.CurrentUserIdF(c)
+}
@@ -0,0 +1,111 @@
+package goapp
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestFormatAccessLogEntry(t *testing.T) {
+	entry := &accessLogEntry{
+		Time:      time.Date(2024, 12, 1, 10, 20, 30, 0, time.UTC),
+		ClientIp:  "10.0.0.1",
+		UserId:    "alice",
+		Method:    "POST",
+		Uri:       "/api/login?x=1",
+		Proto:     "HTTP/1.1",
+		Status:    200,
+		Bytes:     42,
+		LatencyMs: 12.5,
+		UserAgent: "curl/8.0",
+		RequestId: "abc",
+		ApiPath:   "/login",
+	}
+
+	expected := `10.0.0.1 - alice [01/Dec/2024:10:20:30 +0000] "POST /api/login?x=1 HTTP/1.1" 200 42 rt=0.013 rid=abc api=/login` + "\n"
+	if line := string(formatAccessLogEntry(entry, "common")); line != expected {
+		t.Errorf("unexpected common line: %s", line)
+	}
+
+	expected = `10.0.0.1 - alice [01/Dec/2024:10:20:30 +0000] "POST /api/login?x=1 HTTP/1.1" 200 42 "-" "curl/8.0" rt=0.013 rid=abc api=/login` + "\n"
+	if line := string(formatAccessLogEntry(entry, "combined")); line != expected {
+		t.Errorf("unexpected combined line: %s", line)
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal(formatAccessLogEntry(entry, "json"), &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded["user_id"] != "alice" || decoded["request_id"] != "abc" || decoded["api_path"] != "/login" || decoded["latency_ms"] != 12.5 {
+		t.Errorf("unexpected json entry: %v", decoded)
+	}
+}
+
+func TestAccessLogMiddleware(t *testing.T) {
+	var buffer bytes.Buffer
+
+	app := NewAppBase(&testAppSettings{})
+	app.baseSettings.AccessLogFormat = "json"
+	app.baseSettings.AccessLogExcludePaths = []string{"/healthz", "/static/*"}
+	app.CurrentUserIdF = func(c *gin.Context) string { return "bob" }
+	app.accessLog = &buffer
+
+	app.BuildWebRouterF = func(r *gin.Engine) {
+		for _, path := range []string{"/page", "/healthz", "/static/app.js"} {
+			r.GET(path, func(c *gin.Context) { c.String(200, "ok") })
+		}
+	}
+	app.buildWebRouter()
+
+	for _, path := range []string{"/healthz", "/static/app.js", "/page"} {
+		app.webRouter.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != 1 || !strings.Contains(lines[0], `"uri":"/page"`) || !strings.Contains(lines[0], `"user_id":"bob"`) {
+		t.Errorf("unexpected access log: %s", buffer.String())
+	}
+}
+
+func TestAccessLogBytes(t *testing.T) {
+	var buffer bytes.Buffer
+
+	app := NewAppBase(&testAppSettings{})
+	app.baseSettings.AccessLogFormat = "json"
+	app.accessLog = &buffer
+
+	large := strings.Repeat("compressible text ", 1000)
+
+	app.BuildWebRouterF = func(r *gin.Engine) {
+		r.GET("/small", func(c *gin.Context) { c.String(200, "ok") })
+		r.GET("/large", func(c *gin.Context) { c.String(200, large) })
+	}
+	app.buildWebRouter()
+
+	for _, path := range []string{"/small", "/large"} {
+		buffer.Reset()
+
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		request.Header.Set("Accept-Encoding", "gzip")
+
+		w := httptest.NewRecorder()
+		app.webRouter.ServeHTTP(w, request)
+
+		var entry map[string]any
+		if err := json.Unmarshal(buffer.Bytes(), &entry); err != nil {
+			t.Fatalf("%s: %s (%s)", path, err, buffer.String())
+		}
+
+		// bytes actually sent, compressed ones for large reply
+		if entry["bytes"] != float64(w.Body.Len()) || w.Body.Len() == 0 {
+			t.Errorf("%s: logged %v bytes, sent %d", path, entry["bytes"], w.Body.Len())
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package goapp
+
+import (
+	"crypto/subtle"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Admin commands endpoint: GET lists commands, POST to adminCommandsPath/<name> runs one.
+const adminCommandsPath = "/admin/ctl"
+
+// Builds router for admin listener (admin_listen setting) and control socket (control_socket
+// setting) with metrics endpoint and admin commands. Maintenance API and debug endpoints are added
+// by addMaintenanceRoutes() and addDebugEndpoints(). All endpoints are protected by
+// adminAuthMiddleware(), metrics accept metrics_token too.
+func (app *AppBase) buildAdminRouter() {
+	app.adminRouter = nil
+
+	if app.baseSettings.AdminListen == "" && app.baseSettings.ControlSocket == "" {
+		return
+	}
+
+	app.adminRouter = gin.New()
+	app.adminRouter.Use(app.loggerMiddleware)
+	app.adminRouter.Use(gin.RecoveryWithWriter(app.ginWriter(slog.LevelError)))
+
+	if app.MetricsPath != "" {
+		app.adminRouter.GET(app.MetricsPath, app.adminMetricsAuthMiddleware, app.metricsGinHandler)
+	}
+
+	commands := app.adminRouter.Group(adminCommandsPath, app.adminAuthMiddleware)
+	commands.GET("", app.adminCommandsListGinHandler)
+	commands.POST("/:command", app.adminCommandGinHandler)
+}
+
+func (app *AppBase) adminCommandsListGinHandler(c *gin.Context) {
+	list := make([]gin.H, 0, len(app.adminCommands))
+	for _, ac := range app.adminCommands {
+		list = append(list, gin.H{"name": ac.name, "description": ac.description})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "commands": list})
+}
+
+// Runs admin command. Accepts JSON: {"args": ["debug"]}
+func (app *AppBase) adminCommandGinHandler(c *gin.Context) {
+	ac := app.adminCommand(c.Param("command"))
+	if ac == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, adminCommandReply{Status: "error", Message: "unknown admin command: " + c.Param("command")})
+		return
+	}
+
+	var input struct {
+		Args []string `json:"args"`
+	}
+
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, adminCommandReply{Status: "error", Message: err.Error()})
+			return
+		}
+	}
+
+	output, err := ac.handler(c.Request.Context(), input.Args)
+	if err != nil {
+		app.logger.WarnContext(c.Request.Context(), "Admin command failed", "component", LogComponentApp, "command", ac.name, "error", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, adminCommandReply{Status: "error", Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, adminCommandReply{Status: "ok", Output: output})
+}
+
+// Protects admin endpoints on admin router. Control socket is protected by file permissions,
+// admin listener requires admin_token if it is set. Without admin_token they are served on
+// loopback admin listener only.
+func (app *AppBase) adminAuthMiddleware(c *gin.Context) {
+	if isUnixSocketRequest(c) {
+		return
+	}
+
+	if app.baseSettings.AdminToken != "" {
+		bearerTokenMiddleware(app.baseSettings.AdminToken, "admin")(c)
+		return
+	}
+
+	if !isLoopbackAddress(app.baseSettings.AdminListen) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"status": "error", "message": "admin_token is required for admin endpoints on non-loopback admin_listen",
+		})
+	}
+}
+
+// Protects metrics endpoint on admin router: metrics_token is accepted in addition to
+// adminAuthMiddleware() rules.
+func (app *AppBase) adminMetricsAuthMiddleware(c *gin.Context) {
+	if token := app.baseSettings.MetricsToken; token != "" && hasBearerToken(c, token) {
+		return
+	}
+
+	app.adminAuthMiddleware(c)
+}
+
+func isUnixSocketRequest(c *gin.Context) bool {
+	addr, ok := c.Request.Context().Value(http.LocalAddrContextKey).(net.Addr)
+
+	return ok && addr.Network() == "unix"
+}
+
+// Tells if address (host:port) is bound to loopback interface.
+func isLoopbackAddress(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+
+	return ip != nil && ip.IsLoopback()
+}
+
+// Returns admin listener http.Handler or nil if admin listener is disabled. Available after
+// startup only.
+func (app *AppBase) AdminHandler() http.Handler {
+	if app.adminRouter == nil {
+		return nil
+	}
+
+	return app.adminRouter.Handler()
+}
+
+// gin middleware requiring "Authorization: Bearer <token>" header.
+func bearerTokenMiddleware(token, realm string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !hasBearerToken(c, token) {
+			c.Header("WWW-Authenticate", `Bearer realm="`+realm+`"`)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// Checks "Authorization: Bearer <token>" header.
+func hasBearerToken(c *gin.Context, token string) bool {
+	value, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+
+	return found && subtle.ConstantTimeCompare([]byte(value), []byte(token)) == 1
+}
+
+func (app *AppBase) buildAdminServer() *http.Server {
+	if app.adminRouter == nil || app.baseSettings.AdminListen == "" {
+		return nil
+	}
+
+	if !isLoopbackAddress(app.baseSettings.AdminListen) {
+		app.logger.Warn("Admin listener is not bound to loopback address, make sure it is not reachable from outside",
+			"component", LogComponentWeb, "address", app.baseSettings.AdminListen, "admin_token_set", app.baseSettings.AdminToken != "",
+		)
+	}
+
+	return &http.Server{
+		Addr:              app.baseSettings.AdminListen,
+		Handler:           app.adminRouter.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
+
+// Opens control socket (control_socket setting) serving admin router. Returns nil if disabled.
+func (app *AppBase) openControlSocket() (*http.Server, net.Listener, error) {
+	path := app.baseSettings.ControlSocket
+
+	if app.adminRouter == nil || path == "" {
+		return nil, nil, nil
+	}
+
+	if err := removeStaleSocket(path, app.componentLogger(LogComponentWeb)); err != nil {
+		return nil, nil, err
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// file permissions are the only protection
+	if err := os.Chmod(path, 0600); err != nil {
+		l.Close()
+		return nil, nil, err
+	}
+
+	srv := &http.Server{
+		Handler:           app.adminRouter.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	return srv, l, nil
+}
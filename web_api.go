@@ -1,6 +1,7 @@
 package goapp
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,28 +12,48 @@ import (
 
 type (
 	ApiRequest struct {
-		inData  map[string]interface{}
-		outData map[string]interface{}
-		session sessions.Session
+		inData     map[string]interface{}
+		outData    map[string]interface{}
+		session    sessions.Session
+		cookiePath string
 
 		context *gin.Context
 	}
 
 	ApiRequestHandler func(r *ApiRequest) error
+
+	// Option for AppBase.ApiHandler()
+	ApiHandlerOption func(entry *apiHandlerEntry)
+
+	// registered API handler with its options
+	apiHandlerEntry struct {
+		handler     ApiRequestHandler
+		csrfExempt  bool
+		rateLimiter *rateLimiter
+	}
 )
 
-func newApiRequest(c *gin.Context) (*ApiRequest, error) {
+// Disables CSRF protection for handler. Use it for handlers that are safe to call from other sites
+// (no session state changes) or are authenticated by other means (API tokens, signatures).
+func ApiCsrfExempt() ApiHandlerOption {
+	return func(entry *apiHandlerEntry) {
+		entry.csrfExempt = true
+	}
+}
+
+func newApiRequest(c *gin.Context, cookiePath string) (*ApiRequest, error) {
 	r := &ApiRequest{
-		inData:  make(map[string]interface{}),
-		outData: make(map[string]interface{}),
-		context: c,
+		inData:     make(map[string]interface{}),
+		outData:    make(map[string]interface{}),
+		context:    c,
+		cookiePath: cookiePath,
 	}
 
 	//prepare session
 	r.session = sessions.Default(c)
 	r.session.Options(sessions.Options{
 		MaxAge: 24 * 3600,
-		Path:   "/",
+		Path:   cookiePath,
 	})
 
 	//prepare input data
@@ -105,6 +126,17 @@ func (r *ApiRequest) SetErrorStatus(message string) {
 	r.setStatus("error", message)
 }
 
+// Returns request ID (see X-Request-ID header).
+func (r *ApiRequest) RequestId() string {
+	return RequestId(r.context)
+}
+
+// Returns request context. It carries request ID, pass it to slog *Context() methods and
+// WorkerPool.Do*Context() methods.
+func (r *ApiRequest) Context() context.Context {
+	return r.context.Request.Context()
+}
+
 func (r *ApiRequest) Session() sessions.Session {
 	return r.session
 }
@@ -114,7 +146,7 @@ func (r *ApiRequest) SessionClear() {
 
 	r.session.Options(sessions.Options{
 		MaxAge: -1, //remove immediately
-		Path:   "/",
+		Path:   r.cookiePath,
 	})
 
 	r.session.Save()
@@ -127,3 +159,9 @@ func (r *ApiRequest) SessionGet(key string) any {
 func (r *ApiRequest) SessionSet(key string, value any) {
 	r.session.Set(key, value)
 }
+
+// Aborts API request with JSON error reply.
+func apiErrorReply(c *gin.Context, status int, message string) {
+	c.Set(apiErrorGinKey, true)
+	c.AbortWithStatusJSON(status, gin.H{"status": "error", "message": message, "request_id": RequestId(c)})
+}
@@ -0,0 +1,211 @@
+package goapp
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+// Compressing writer (gzip.Writer and brotli.Writer)
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+var compressorPools = map[string]*sync.Pool{
+	"br": {New: func() any { return brotli.NewWriterLevel(io.Discard, 5) }},
+	"gzip": {New: func() any {
+		w, _ := gzip.NewWriterLevel(io.Discard, gzip.DefaultCompression)
+		return w
+	}},
+}
+
+// gin middleware compressing responses with brotli or gzip depending on Accept-Encoding.
+//
+// Response is buffered until webserver_compression_min_size bytes written: smaller responses are
+// sent as is. Responses with Content-Encoding already set (precompressed static files), not
+// listed content types, range requests and responses flushed before reaching min size (streaming)
+// are not compressed.
+func (app *AppBase) compressionMiddleware(c *gin.Context) {
+	if c.Request.Method == http.MethodHead || c.GetHeader("Range") != "" {
+		return
+	}
+
+	w := &compressWriter{
+		ResponseWriter: c.Writer,
+		encoding:       negotiateEncoding(c.GetHeader("Accept-Encoding")),
+		minSize:        app.baseSettings.WebserverCompressionMinSize,
+		types:          app.baseSettings.WebserverCompressionTypes,
+	}
+
+	c.Writer = w
+	defer func() {
+		w.finish()
+		c.Writer = w.ResponseWriter
+	}()
+
+	c.Next()
+}
+
+// Returns best supported encoding accepted by client. Empty string if none.
+func negotiateEncoding(acceptEncoding string) string {
+	for _, encoding := range []string{"br", "gzip"} {
+		if acceptsEncoding(acceptEncoding, encoding) {
+			return encoding
+		}
+	}
+
+	return ""
+}
+
+type compressWriter struct {
+	gin.ResponseWriter
+
+	encoding string
+	minSize  int
+	types    []string
+
+	buffer     []byte
+	decided    bool
+	compressor compressor
+}
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if w.decided {
+		if w.compressor != nil {
+			return w.compressor.Write(p)
+		}
+
+		return w.ResponseWriter.Write(p)
+	}
+
+	w.buffer = append(w.buffer, p...)
+
+	if len(w.buffer) >= w.minSize {
+		if err := w.decide(); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *compressWriter) Written() bool {
+	return w.ResponseWriter.Written() || len(w.buffer) > 0
+}
+
+// Headers are sent immediately, body written after this is not compressed.
+func (w *compressWriter) WriteHeaderNow() {
+	if !w.decided {
+		w.decide()
+	}
+
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// For http.ResponseController (write deadlines etc).
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Streaming responses: buffered data is sent (uncompressed if min size is not reached yet).
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide()
+	}
+
+	if w.compressor != nil {
+		w.compressor.Flush()
+	}
+
+	w.ResponseWriter.Flush()
+}
+
+// Decides if response should be compressed and writes buffered data.
+func (w *compressWriter) decide() error {
+	w.decided = true
+
+	header := w.Header()
+
+	if header.Get("Content-Type") == "" && len(w.buffer) > 0 {
+		header.Set("Content-Type", http.DetectContentType(w.buffer))
+	}
+
+	compressible := w.isCompressibleType(header.Get("Content-Type")) && header.Get("Content-Encoding") == ""
+
+	if compressible && !slices.ContainsFunc(header.Values("Vary"), func(v string) bool {
+		return strings.Contains(strings.ToLower(v), "accept-encoding")
+	}) {
+		header.Add("Vary", "Accept-Encoding")
+	}
+
+	status := w.Status()
+
+	if compressible && w.encoding != "" && len(w.buffer) >= w.minSize && header.Get("Content-Range") == "" &&
+		status >= 200 && status != http.StatusNoContent && status != http.StatusNotModified {
+		header.Del("Content-Length")
+		header.Set("Content-Encoding", w.encoding)
+
+		// compressed representation differs from uncompressed one
+		if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			header.Set("ETag", "W/"+etag)
+		}
+
+		w.compressor = compressorPools[w.encoding].Get().(compressor)
+		w.compressor.Reset(w.ResponseWriter)
+	}
+
+	buffer := w.buffer
+	w.buffer = nil
+
+	if len(buffer) == 0 {
+		return nil
+	}
+
+	if w.compressor != nil {
+		_, err := w.compressor.Write(buffer)
+		return err
+	}
+
+	_, err := w.ResponseWriter.Write(buffer)
+	return err
+}
+
+// Writes buffered data and completes compressed stream.
+func (w *compressWriter) finish() {
+	if !w.decided {
+		if len(w.buffer) == 0 {
+			return // nothing written, leave headers untouched
+		}
+
+		w.decide()
+	}
+
+	if w.compressor != nil {
+		w.compressor.Close()
+		w.compressor.Reset(io.Discard)
+		compressorPools[w.encoding].Put(w.compressor)
+		w.compressor = nil
+	}
+}
+
+func (w *compressWriter) isCompressibleType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return slices.Contains(w.types, mediaType)
+}
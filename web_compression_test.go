@@ -0,0 +1,85 @@
+package goapp
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+func TestCompression(t *testing.T) {
+	app := NewAppBase(&testAppSettings{})
+	app.baseSettings.SecurityHeadersDisabled = true
+
+	large := strings.Repeat("compress me ", 200)
+
+	app.ServeStatic("/static", fstest.MapFS{
+		"app.js":    {Data: []byte(large)},
+		"app.js.gz": {Data: []byte("precompressed")},
+	}, nil)
+
+	app.BuildWebRouterF = func(r *gin.Engine) {
+		r.GET("/large", func(c *gin.Context) { c.String(200, large) })
+		r.GET("/small", func(c *gin.Context) { c.String(200, "small") })
+		r.GET("/image", func(c *gin.Context) { c.Data(200, "image/png", []byte(large)) })
+		r.GET("/stream", func(c *gin.Context) {
+			c.Header("Content-Type", "text/plain")
+			c.Writer.WriteString("event")
+			c.Writer.Flush()
+			c.Writer.WriteString(large)
+		})
+	}
+	app.buildWebRouter()
+
+	get := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		request.Header.Set("Accept-Encoding", acceptEncoding)
+
+		w := httptest.NewRecorder()
+		app.webRouter.ServeHTTP(w, request)
+
+		return w
+	}
+
+	for encoding, newReader := range map[string]func(io.Reader) (io.Reader, error){
+		"gzip": func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+		"br":   func(r io.Reader) (io.Reader, error) { return brotli.NewReader(r), nil },
+	} {
+		w := get("/large", "deflate, "+encoding)
+
+		if w.Header().Get("Content-Encoding") != encoding || w.Header().Get("Vary") != "Accept-Encoding" {
+			t.Errorf("%s: unexpected headers %v", encoding, w.Header())
+			continue
+		}
+
+		reader, err := newReader(w.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if body, err := io.ReadAll(reader); err != nil || string(body) != large {
+			t.Errorf("%s: body is not decoded: %v", encoding, err)
+		}
+	}
+
+	if w := get("/large", "br;q=0, gzip"); w.Header().Get("Content-Encoding") != "gzip" {
+		t.Errorf("br with q=0 should not be used: %v", w.Header())
+	}
+
+	for _, path := range []string{"/small", "/image", "/stream"} {
+		if w := get(path, "gzip"); w.Header().Get("Content-Encoding") != "" {
+			t.Errorf("%s should not be compressed", path)
+		}
+	}
+
+	// precompressed variant is served as is
+	if w := get("/static/app.js", "gzip"); w.Header().Get("Content-Encoding") != "gzip" || w.Body.String() != "precompressed" {
+		t.Errorf("precompressed file is compressed again: %v %s", w.Header(), w.Body.String())
+	}
+}
@@ -0,0 +1,127 @@
+package goapp
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Checks if origin matches CORS origin pattern: "*", exact origin or origin with wildcard
+// subdomain like "https://*.example.com".
+func matchCorsOrigin(pattern, origin string) bool {
+	pattern = strings.ToLower(strings.TrimSuffix(pattern, "/"))
+	origin = strings.ToLower(origin)
+
+	if pattern == "*" || pattern == origin {
+		return true
+	}
+
+	scheme, host, found := strings.Cut(pattern, "://*.")
+	if !found {
+		return false
+	}
+
+	prefix := scheme + "://"
+	suffix := "." + host
+
+	return strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) &&
+		len(origin) > len(prefix)+len(suffix) && !strings.ContainsAny(origin[len(prefix):len(origin)-len(suffix)], "/:")
+}
+
+// Returns Access-Control-Allow-Origin value if request Origin is allowed by web_api_cors_origins:
+// request origin itself or "*" if it is allowed by "*" pattern only (no credentials then).
+func (app *AppBase) allowedCorsOrigin(c *gin.Context) (string, bool) {
+	origin := c.GetHeader("Origin")
+	if origin == "" {
+		return "", false
+	}
+
+	anyOrigin := false
+
+	for _, pattern := range app.baseSettings.WebApiCorsOrigins {
+		if pattern == "*" {
+			anyOrigin = true
+		} else if matchCorsOrigin(pattern, origin) {
+			return origin, true
+		}
+	}
+
+	if anyOrigin {
+		return "*", true
+	}
+
+	return "", false
+}
+
+// Methods allowed for CORS requests.
+func (app *AppBase) corsMethods() []string {
+	if len(app.baseSettings.WebApiCorsMethods) > 0 {
+		return app.baseSettings.WebApiCorsMethods
+	}
+
+	if app.WebApiEnableGet {
+		return []string{http.MethodPost, http.MethodGet}
+	}
+
+	return []string{http.MethodPost}
+}
+
+// Sets CORS headers for actual (not preflight) API request.
+func (app *AppBase) setCorsHeaders(c *gin.Context) {
+	if len(app.baseSettings.WebApiCorsOrigins) == 0 {
+		return
+	}
+
+	c.Writer.Header().Add("Vary", "Origin")
+
+	origin, ok := app.allowedCorsOrigin(c)
+	if !ok {
+		return
+	}
+
+	c.Header("Access-Control-Allow-Origin", origin)
+	c.Header("Access-Control-Expose-Headers", CsrfHeaderName)
+
+	// browsers do not allow credentials with "*"
+	if app.baseSettings.WebApiCorsCredentials && origin != "*" {
+		c.Header("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// Answers OPTIONS preflight requests for registered API paths.
+func (app *AppBase) webApiPreflightGinHandler(c *gin.Context) {
+	path := strings.TrimPrefix(c.Request.URL.Path, app.WebApiPathPrefix)
+
+	if _, ok := app.webApiHandlerList[path]; !ok {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	c.Writer.Header().Add("Vary", "Origin")
+	c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+	c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+
+	origin, ok := app.allowedCorsOrigin(c)
+	methods := app.corsMethods()
+
+	if !ok || !slices.Contains(methods, strings.ToUpper(c.GetHeader("Access-Control-Request-Method"))) {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	c.Header("Access-Control-Allow-Origin", origin)
+	c.Header("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+	c.Header("Access-Control-Allow-Headers", strings.Join(app.baseSettings.WebApiCorsHeaders, ", "))
+
+	if app.baseSettings.WebApiCorsCredentials && origin != "*" {
+		c.Header("Access-Control-Allow-Credentials", "true")
+	}
+
+	// negative value = no caching (without header browsers cache preflight replies for 5 seconds)
+	c.Header("Access-Control-Max-Age", strconv.Itoa(max(app.baseSettings.WebApiCorsMaxAge, 0)))
+
+	c.AbortWithStatus(http.StatusNoContent)
+}
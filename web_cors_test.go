@@ -0,0 +1,96 @@
+package goapp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMatchCorsOrigin(t *testing.T) {
+	for _, c := range []struct {
+		pattern, origin string
+		expected        bool
+	}{
+		{"*", "https://any.site", true},
+		{"https://app.example.com", "https://app.example.com", true},
+		{"https://app.example.com/", "https://APP.example.com", true},
+		{"https://app.example.com", "http://app.example.com", false},
+		{"https://*.example.com", "https://a.example.com", true},
+		{"https://*.example.com", "https://a.b.example.com", true},
+		{"https://*.example.com", "https://example.com", false},
+		{"https://*.example.com", "https://evil-example.com", false},
+		{"https://*.example.com", "https://a.example.com.evil.net", false},
+		{"https://*.example.com:8443", "https://a.example.com:8443", true},
+	} {
+		if matchCorsOrigin(c.pattern, c.origin) != c.expected {
+			t.Errorf("pattern '%s', origin '%s': expected %v", c.pattern, c.origin, c.expected)
+		}
+	}
+}
+
+func TestApiCors(t *testing.T) {
+	app := NewAppBase(&testAppSettings{})
+	app.WebApiPathPrefix = "/api"
+	app.baseSettings.WebserverCookieSecret = "test"
+	app.baseSettings.WebApiCorsOrigins = []string{"https://*.example.com"}
+	app.baseSettings.WebApiCorsCredentials = true
+
+	app.ApiHandler("/save", func(r *ApiRequest) error { return nil })
+	app.buildWebRouter()
+
+	preflight := func(path, origin, method string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodOptions, path, nil)
+		request.Header.Set("Origin", origin)
+		request.Header.Set("Access-Control-Request-Method", method)
+
+		w := httptest.NewRecorder()
+		app.webRouter.ServeHTTP(w, request)
+
+		return w
+	}
+
+	w := preflight("/api/save", "https://app.example.com", "POST")
+
+	if w.Code != http.StatusNoContent ||
+		w.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" ||
+		w.Header().Get("Access-Control-Allow-Credentials") != "true" ||
+		w.Header().Get("Access-Control-Allow-Headers") != "Content-Type, X-CSRF-Token" ||
+		w.Header().Get("Access-Control-Max-Age") != "600" {
+		t.Errorf("unexpected preflight reply: %d %v", w.Code, w.Header())
+	}
+
+	if w := preflight("/api/unknown", "https://app.example.com", "POST"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown path preflight: expected 404, got %d", w.Code)
+	}
+
+	if w := preflight("/api/save", "https://evil.net", "POST"); w.Code != http.StatusForbidden {
+		t.Errorf("foreign origin preflight: expected 403, got %d", w.Code)
+	}
+
+	if w := preflight("/api/save", "https://app.example.com", "DELETE"); w.Code != http.StatusForbidden {
+		t.Errorf("not allowed method preflight: expected 403, got %d", w.Code)
+	}
+
+	app.baseSettings.WebApiCorsMaxAge = -1
+
+	if w := preflight("/api/save", "https://app.example.com", "POST"); w.Header().Get("Access-Control-Max-Age") != "0" {
+		t.Errorf("no caching preflight: expected Max-Age 0, got '%s'", w.Header().Get("Access-Control-Max-Age"))
+	}
+
+	// actual request gets CORS headers, CORS origin passes CSRF origin check
+	request := httptest.NewRequest(http.MethodPost, "/api/save", nil)
+	request.Header.Set("Origin", "https://app.example.com")
+
+	w = httptest.NewRecorder()
+	app.webRouter.ServeHTTP(w, request)
+
+	if w.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" ||
+		w.Header().Get("Access-Control-Expose-Headers") != CsrfHeaderName {
+		t.Errorf("no CORS headers in reply: %v", w.Header())
+	}
+
+	if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), "CSRF token") {
+		t.Errorf("CORS origin should pass CSRF origin check: %d %s", w.Code, w.Body.String())
+	}
+}
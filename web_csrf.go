@@ -0,0 +1,125 @@
+package goapp
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+// Header to pass CSRF token with web API requests. Every API reply has current token in this header.
+const CsrfHeaderName = "X-CSRF-Token"
+
+const csrfSessionKey = "goapp.csrf_token"
+
+// Built-in web API path (without WebApiPathPrefix) returning CSRF token: {"csrf_token": "..."}.
+// Lets API-only clients get token before first state-changing request.
+const CsrfTokenApiPath = "/csrf-token"
+
+// Returns CSRF token for current session. New token is generated and saved to session if there
+// is no one yet. Available in templates as {{csrfToken}}.
+func CsrfToken(c *gin.Context) string {
+	session := sessions.Default(c)
+
+	if token, ok := session.Get(csrfSessionKey).(string); ok && token != "" {
+		return token
+	}
+
+	tokenBytes := make([]byte, 32)
+	rand.Read(tokenBytes)
+	token := base64.RawURLEncoding.EncodeToString(tokenBytes)
+
+	session.Set(csrfSessionKey, token)
+
+	if err := session.Save(); err != nil {
+		ginComponentLogger(c, LogComponentWeb).ErrorContext(c.Request.Context(), "CSRF token saving failed", "error", err)
+	}
+
+	return token
+}
+
+// Registers CsrfTokenApiPath handler unless application has own one.
+func (app *AppBase) addCsrfTokenApiHandler() {
+	if app.baseSettings.WebApiCsrfDisabled {
+		return
+	}
+
+	if _, exists := app.webApiHandlerList[CsrfTokenApiPath]; !exists {
+		app.ApiHandler(CsrfTokenApiPath, csrfTokenApiHandler, ApiCsrfExempt())
+	}
+}
+
+func csrfTokenApiHandler(r *ApiRequest) error {
+	r.SetOutData("csrf_token", CsrfToken(r.context))
+
+	return nil
+}
+
+// Checks that web API request came from trusted origin and has valid CSRF token.
+func (app *AppBase) checkCsrf(c *gin.Context) error {
+	// GET API requests can change state too when web_api_enable_get is on, so they are checked then
+	if !app.WebApiEnableGet && (c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead) {
+		return nil
+	}
+
+	origin := c.GetHeader("Origin")
+
+	if origin == "" {
+		if referer, err := url.Parse(c.GetHeader("Referer")); err == nil && referer.Host != "" {
+			origin = referer.Scheme + "://" + referer.Host
+		}
+	}
+
+	// no Origin and no Referer: not a browser request or privacy settings, rely on token only
+	if origin != "" && !app.isTrustedOrigin(c, origin) {
+		return errors.New("request origin '" + origin + "' is not allowed")
+	}
+
+	expected, _ := sessions.Default(c).Get(csrfSessionKey).(string)
+	token := c.GetHeader(CsrfHeaderName)
+
+	if expected == "" || subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+		return errors.New("invalid or missing CSRF token")
+	}
+
+	return nil
+}
+
+// Checks if origin (scheme://host[:port]) is same as BaseUrl, request host or listed in web_api_trusted_origins
+// or web_api_cors_origins.
+func (app *AppBase) isTrustedOrigin(c *gin.Context, origin string) bool {
+	originUrl, err := url.Parse(origin)
+	if err != nil || originUrl.Host == "" {
+		return false
+	}
+
+	// browsers always send real target host, so same host means same site
+	if strings.EqualFold(originUrl.Host, c.Request.Host) {
+		return true
+	}
+
+	if baseUrl, err := url.Parse(app.baseSettings.BaseUrl); err == nil &&
+		strings.EqualFold(originUrl.Scheme, baseUrl.Scheme) && strings.EqualFold(originUrl.Host, baseUrl.Host) {
+		return true
+	}
+
+	origin = strings.ToLower(originUrl.Scheme + "://" + originUrl.Host)
+
+	// explicitly listed CORS origins are trusted too ("*" is not)
+	for _, pattern := range app.baseSettings.WebApiCorsOrigins {
+		if pattern != "*" && matchCorsOrigin(pattern, origin) {
+			return true
+		}
+	}
+
+	return slices.ContainsFunc(app.baseSettings.WebApiTrustedOrigins, func(trusted string) bool {
+		return strings.ToLower(strings.TrimSuffix(trusted, "/")) == origin
+	})
+}
@@ -0,0 +1,123 @@
+package goapp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestApiCsrf(t *testing.T) {
+	app := NewAppBase(&testAppSettings{})
+	app.WebApiPathPrefix = "/api"
+	app.baseSettings.BaseUrl = "https://example.com"
+	app.baseSettings.WebserverCookieSecret = "test"
+	app.baseSettings.WebApiTrustedOrigins = []string{"https://app.example.com"}
+
+	app.ApiHandler("/save", func(r *ApiRequest) error { return nil })
+	app.ApiHandler("/webhook", func(r *ApiRequest) error { return nil }, ApiCsrfExempt())
+	app.buildWebRouter()
+
+	call := func(path, origin, token string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, path, nil)
+		request.Host = "internal:15115"
+
+		if origin != "" {
+			request.Header.Set("Origin", origin)
+		}
+
+		if token != "" {
+			request.Header.Set(CsrfHeaderName, token)
+		}
+
+		for _, cookie := range cookies {
+			request.AddCookie(cookie)
+		}
+
+		w := httptest.NewRecorder()
+		app.webRouter.ServeHTTP(w, request)
+
+		return w
+	}
+
+	// no token
+	w := call("/api/save", "", "", nil)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("request without token: expected 403, got %d", w.Code)
+	}
+
+	token := w.Header().Get(CsrfHeaderName)
+	cookies := w.Result().Cookies()
+
+	if token == "" || len(cookies) == 0 {
+		t.Fatal("no CSRF token issued")
+	}
+
+	for _, origin := range []string{"", "https://example.com", "https://app.example.com", "http://internal:15115"} {
+		if w := call("/api/save", origin, token, cookies); w.Code != http.StatusOK {
+			t.Errorf("origin '%s': expected 200, got %d", origin, w.Code)
+		}
+	}
+
+	if w := call("/api/save", "https://evil.example.net", token, cookies); w.Code != http.StatusForbidden {
+		t.Errorf("foreign origin: expected 403, got %d", w.Code)
+	}
+
+	if w := call("/api/save", "", "wrong", cookies); w.Code != http.StatusForbidden {
+		t.Errorf("wrong token: expected 403, got %d", w.Code)
+	}
+
+	if w := call("/api/webhook", "https://evil.example.net", "", nil); w.Code != http.StatusOK {
+		t.Errorf("exempt handler: expected 200, got %d", w.Code)
+	}
+}
+
+func TestApiCsrfTokenEndpoint(t *testing.T) {
+	app := NewAppBase(&testAppSettings{})
+	app.WebApiPathPrefix = "/api"
+	app.WebApiEnableGet = true
+	app.baseSettings.WebserverCookieSecret = "test"
+
+	app.ApiHandler("/delete", func(r *ApiRequest) error { return nil })
+	app.buildWebRouter()
+
+	call := func(method, path, token string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, path, nil)
+
+		if token != "" {
+			request.Header.Set(CsrfHeaderName, token)
+		}
+
+		for _, cookie := range cookies {
+			request.AddCookie(cookie)
+		}
+
+		w := httptest.NewRecorder()
+		app.webRouter.ServeHTTP(w, request)
+
+		return w
+	}
+
+	// GET API requests are checked when they are enabled
+	if w := call(http.MethodGet, "/api/delete", "", nil); w.Code != http.StatusForbidden {
+		t.Errorf("GET without token: expected 403, got %d", w.Code)
+	}
+
+	w := call(http.MethodGet, "/api"+CsrfTokenApiPath, "", nil)
+
+	var out map[string]any
+	json.Unmarshal(w.Body.Bytes(), &out)
+
+	token, _ := out["csrf_token"].(string)
+	if w.Code != http.StatusOK || token == "" || token != w.Header().Get(CsrfHeaderName) {
+		t.Fatalf("unexpected token endpoint reply: %d %s", w.Code, w.Body.String())
+	}
+
+	cookies := w.Result().Cookies()
+
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		if w := call(method, "/api/delete", token, cookies); w.Code != http.StatusOK {
+			t.Errorf("%s with token: expected 200, got %d", method, w.Code)
+		}
+	}
+}
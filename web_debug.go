@@ -0,0 +1,133 @@
+package goapp
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	rpprof "runtime/pprof"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Debug endpoints are served under this prefix (pprof index page links depend on it).
+const debugPathPrefix = "/debug"
+
+// Tells if debug_endpoints are enabled and allowed in current mode.
+func (app *AppBase) debugEndpointsAllowed() bool {
+	if !app.baseSettings.DebugEndpoints {
+		return false
+	}
+
+	if !app.IsDevMode() && !app.baseSettings.DebugEndpointsInProduction {
+		app.logger.Warn("Debug endpoints are refused in production mode, set debug_endpoints_in_production to allow them",
+			"component", LogComponentWeb,
+		)
+		return false
+	}
+
+	return true
+}
+
+// Registers debug endpoints on admin listener and on main webserver if admin_token is set.
+func (app *AppBase) addDebugEndpoints() {
+	if !app.debugEndpointsAllowed() {
+		return
+	}
+
+	if app.adminRouter == nil && app.baseSettings.AdminToken == "" {
+		app.logger.Warn("Debug endpoints are not served: set admin_listen or admin_token", "component", LogComponentWeb)
+		return
+	}
+
+	if app.adminRouter != nil {
+		app.addDebugRoutes(app.adminRouter, app.adminAuthMiddleware)
+	}
+
+	if app.baseSettings.AdminToken != "" {
+		app.addDebugRoutes(app.webRouter, bearerTokenMiddleware(app.baseSettings.AdminToken, "admin"))
+	}
+}
+
+// Registers debug endpoints:
+//
+//	/debug/pprof/*     - net/http/pprof profiles
+//	/debug/goroutines  - full goroutines dump
+//	/debug/runtime     - runtime stats (JSON)
+func (app *AppBase) addDebugRoutes(r *gin.Engine, handlers ...gin.HandlerFunc) {
+	group := r.Group(debugPathPrefix, handlers...)
+
+	group.GET("/pprof/*name", debugPprofGinHandler)
+	group.POST("/pprof/symbol", gin.WrapF(pprof.Symbol))
+	group.GET("/goroutines", debugGoroutinesGinHandler)
+	group.GET("/runtime", app.debugRuntimeGinHandler)
+}
+
+func debugPprofGinHandler(c *gin.Context) {
+	// profiles can take longer than server write timeout
+	http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	switch name := c.Param("name"); name {
+	case "/":
+		pprof.Index(c.Writer, c.Request)
+	case "/cmdline":
+		pprof.Cmdline(c.Writer, c.Request)
+	case "/profile":
+		pprof.Profile(c.Writer, c.Request)
+	case "/symbol":
+		pprof.Symbol(c.Writer, c.Request)
+	case "/trace":
+		pprof.Trace(c.Writer, c.Request)
+	default:
+		pprof.Handler(name[1:]).ServeHTTP(c.Writer, c.Request)
+	}
+}
+
+func debugGoroutinesGinHandler(c *gin.Context) {
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+
+	rpprof.Lookup("goroutine").WriteTo(c.Writer, 2)
+}
+
+func (app *AppBase) debugRuntimeGinHandler(c *gin.Context) {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	var lastGc time.Time
+	if ms.LastGC > 0 {
+		lastGc = time.Unix(0, int64(ms.LastGC))
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.IndentedJSON(http.StatusOK, gin.H{
+		"version":        app.Version,
+		"go_version":     app.BuildWith,
+		"uptime_seconds": time.Since(processStartTime).Seconds(),
+		"goroutines":     runtime.NumGoroutine(),
+		"cpus":           runtime.NumCPU(),
+		"gomaxprocs":     runtime.GOMAXPROCS(0),
+		"cgo_calls":      runtime.NumCgoCall(),
+		"memory": gin.H{
+			"alloc_bytes":       ms.Alloc,
+			"total_alloc_bytes": ms.TotalAlloc,
+			"sys_bytes":         ms.Sys,
+			"heap_alloc_bytes":  ms.HeapAlloc,
+			"heap_inuse_bytes":  ms.HeapInuse,
+			"heap_idle_bytes":   ms.HeapIdle,
+			"heap_objects":      ms.HeapObjects,
+			"stack_inuse_bytes": ms.StackInuse,
+			"mallocs":           ms.Mallocs,
+			"frees":             ms.Frees,
+		},
+		"gc": gin.H{
+			"cycles":              ms.NumGC,
+			"forced_cycles":       ms.NumForcedGC,
+			"pause_total_seconds": time.Duration(ms.PauseTotalNs).Seconds(),
+			"last_gc":             lastGc,
+			"next_gc_bytes":       ms.NextGC,
+			"cpu_fraction":        ms.GCCPUFraction,
+		},
+	})
+}
@@ -0,0 +1,96 @@
+package goapp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDebugEndpoints(t *testing.T) {
+	app := NewAppBase(&testAppSettings{})
+	app.baseSettings.DebugEndpoints = true
+	app.baseSettings.AdminToken = "secret"
+	app.baseSettings.AdminListen = "127.0.0.1:0"
+	app.buildWebRouter()
+
+	get := func(h http.Handler, path, token string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			request.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, request)
+
+		return w
+	}
+
+	for _, token := range []string{"", "wrong"} {
+		if w := get(app.webRouter, "/debug/runtime", token); w.Code != http.StatusUnauthorized {
+			t.Errorf("debug endpoint served with token '%s': %d", token, w.Code)
+		}
+	}
+
+	w := get(app.webRouter, "/debug/runtime", "secret")
+
+	var stats map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &stats); err != nil || w.Code != 200 || stats["goroutines"] == nil {
+		t.Errorf("unexpected runtime stats reply: %d %s", w.Code, w.Body.String())
+	}
+
+	if w := get(app.webRouter, "/debug/goroutines", "secret"); w.Code != 200 || !strings.Contains(w.Body.String(), "goroutine ") {
+		t.Errorf("unexpected goroutines reply: %d", w.Code)
+	}
+
+	// admin listener requires token when it is set
+	if w := get(app.AdminHandler(), "/debug/pprof/", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("admin listener debug endpoint served without token: %d", w.Code)
+	}
+
+	if w := get(app.AdminHandler(), "/debug/pprof/", "secret"); w.Code != 200 || !strings.Contains(w.Body.String(), "heap") {
+		t.Errorf("unexpected pprof index reply: %d", w.Code)
+	}
+
+	if w := get(app.AdminHandler(), "/debug/pprof/heap?debug=1", "secret"); w.Code != 200 || !strings.Contains(w.Body.String(), "heap profile") {
+		t.Errorf("unexpected heap profile reply: %d", w.Code)
+	}
+
+	// no token: loopback admin listener only
+	app.baseSettings.AdminToken = ""
+
+	for address, expected := range map[string]int{"127.0.0.1:0": 200, "localhost:0": 200, "[::1]:0": 200, "0.0.0.0:0": http.StatusForbidden, "10.0.0.5:0": http.StatusForbidden} {
+		app.baseSettings.AdminListen = address
+		app.buildWebRouter()
+
+		if w := get(app.AdminHandler(), "/debug/runtime", ""); w.Code != expected {
+			t.Errorf("admin_listen %s without token: expected %d, got %d", address, expected, w.Code)
+		}
+	}
+}
+
+func TestDebugEndpointsInProduction(t *testing.T) {
+	app := NewAppBase(&testAppSettings{})
+	app.Version = "1.0.0"
+	app.baseSettings.DebugEndpoints = true
+	app.baseSettings.AdminListen = "127.0.0.1:0"
+	app.buildWebRouter()
+
+	w := httptest.NewRecorder()
+	app.AdminHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/runtime", nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("debug endpoints served in production: %d", w.Code)
+	}
+
+	app.baseSettings.DebugEndpointsInProduction = true
+	app.buildWebRouter()
+
+	w = httptest.NewRecorder()
+	app.AdminHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/runtime", nil))
+
+	if w.Code != 200 {
+		t.Errorf("debug endpoints are not served in production when allowed: %d", w.Code)
+	}
+}
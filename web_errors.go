@@ -0,0 +1,241 @@
+package goapp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// gin context key marking 5xx reply as reported already or expected (maintenance mode, failed
+// readiness), so errorsMiddleware does not report it
+const errorReportedGinKey = "goapp.error_reported"
+
+// Receives panics and 5xx errors, set AppBase.ErrorReporter to send them to error tracking
+// service. ReportError() is called synchronously from request goroutine, so it should not block.
+type ErrorReporter interface {
+	ReportError(ctx context.Context, report *ErrorReport)
+}
+
+// Error passed to ErrorReporter.
+type ErrorReport struct {
+	IncidentId string
+	Status     int
+	Err        error
+	Panic      any    // recovered value if error is a panic
+	Stack      []byte // stack trace for panics
+	Request    *http.Request
+	RequestId  string
+	UserId     string // see AppBase.CurrentUserIdF
+	Time       time.Time
+}
+
+// Error page data passed to "errors/<status>" (or "errors/error") page templates as {{.Data}}.
+// Built-in page is used if application templates have no such pages.
+type ErrorPage struct {
+	Status     int
+	Title      string // status text
+	Message    string // message for users
+	IncidentId string // 5xx errors only, to find error in logs
+	RequestId  string
+	Details    string // error and stack trace, DEV mode only
+}
+
+// Replies with error page (or JSON for API and Accept: json requests) and aborts request. 5xx
+// errors are logged and passed to ErrorReporter. err can be nil.
+func (app *AppBase) ErrorReply(c *gin.Context, status int, err error) {
+	app.errorReply(c, status, err, nil, nil)
+}
+
+func (app *AppBase) errorReply(c *gin.Context, status int, err error, panicValue any, stack []byte) {
+	page := &ErrorPage{
+		Status:    status,
+		Title:     http.StatusText(status),
+		Message:   errorPageMessage(status),
+		RequestId: RequestId(c),
+	}
+
+	if status >= 500 {
+		c.Set(errorReportedGinKey, true)
+		page.IncidentId = newIncidentId()
+		app.reportError(c, page.IncidentId, status, err, panicValue, stack)
+
+		if app.IsDevMode() {
+			if err != nil {
+				page.Details = err.Error()
+			}
+
+			if stack != nil {
+				page.Details += "\n\n" + string(stack)
+			}
+		}
+	} else if err != nil {
+		// 4xx error messages are meant for users
+		page.Message = err.Error()
+	}
+
+	c.Header("Cache-Control", "no-store")
+
+	if c.Writer.Written() {
+		// too late to change anything
+		c.Abort()
+		return
+	}
+
+	if app.isJsonRequest(c) {
+		reply := gin.H{"status": "error", "message": page.Message, "request_id": page.RequestId}
+
+		if page.IncidentId != "" {
+			reply["incident_id"] = page.IncidentId
+		}
+
+		if app.IsDevMode() && status >= 500 && err != nil {
+			reply["message"] = err.Error()
+		}
+
+		c.Set(apiErrorGinKey, true)
+		c.AbortWithStatusJSON(status, reply)
+		return
+	}
+
+	if app.templates != nil {
+		for _, name := range []string{fmt.Sprintf("errors/%d", status), "errors/error"} {
+			if _, tErr := app.templates.page(name); tErr == nil {
+				app.RenderStatus(c, status, name, page)
+				c.Abort()
+				return
+			}
+		}
+	}
+
+	c.Data(status, "text/html; charset=utf-8", []byte(builtInErrorPage(page, CspNonce(c))))
+	c.Abort()
+}
+
+// Logs error and passes it to ErrorReporter.
+func (app *AppBase) reportError(c *gin.Context, incidentId string, status int, err error, panicValue any, stack []byte) {
+	if err == nil {
+		err = errors.New(http.StatusText(status))
+	}
+
+	logArgs := []any{"component", LogComponentWeb, "incident_id", incidentId, "status", status,
+		"method", c.Request.Method, "path", c.Request.URL.Path, "error", err}
+
+	if stack != nil {
+		logArgs = append(logArgs, "stack", string(stack))
+	}
+
+	app.logger.ErrorContext(c.Request.Context(), "Request failed", logArgs...)
+
+	if app.ErrorReporter == nil {
+		return
+	}
+
+	// reporter failures should not break error reply
+	defer func() {
+		if p := recover(); p != nil {
+			app.logger.ErrorContext(c.Request.Context(), "Error reporter panic", "component", LogComponentWeb, "panic", p)
+		}
+	}()
+
+	app.ErrorReporter.ReportError(c.Request.Context(), &ErrorReport{
+		IncidentId: incidentId,
+		Status:     status,
+		Err:        err,
+		Panic:      panicValue,
+		Stack:      stack,
+		Request:    c.Request,
+		RequestId:  RequestId(c),
+		UserId:     app.currentUserId(c),
+		Time:       time.Now(),
+	})
+}
+
+// gin middleware recovering from panics and replying with error page for errors added with
+// c.Error() by handlers that did not write reply. 5xx replies written by handlers themselves
+// (c.JSON(500, ...), c.AbortWithStatus(503) etc) are reported too.
+func (app *AppBase) errorsMiddleware(c *gin.Context) {
+	defer func() {
+		p := recover()
+		if p == nil {
+			return
+		}
+
+		if p == http.ErrAbortHandler {
+			panic(p) // net/http handles it quietly
+		}
+
+		app.errorReply(c, http.StatusInternalServerError, fmt.Errorf("panic: %v", p), p, debug.Stack())
+	}()
+
+	c.Next()
+
+	if len(c.Errors) > 0 && !c.Writer.Written() {
+		app.ErrorReply(c, http.StatusInternalServerError, c.Errors.Last().Err)
+		return
+	}
+
+	if status := c.Writer.Status(); status >= 500 && !c.GetBool(errorReportedGinKey) {
+		var err error
+		if len(c.Errors) > 0 {
+			err = c.Errors.Last().Err
+		}
+
+		app.reportError(c, newIncidentId(), status, err, nil, nil)
+	}
+}
+
+// NoRoute handler (after static files one).
+func (app *AppBase) notFoundGinHandler(c *gin.Context) {
+	app.ErrorReply(c, http.StatusNotFound, nil)
+}
+
+func errorPageMessage(status int) string {
+	switch {
+	case status == http.StatusNotFound:
+		return "The page you are looking for does not exist."
+	case status >= 500:
+		return "Something went wrong on our side. Please try again later."
+	default:
+		return http.StatusText(status)
+	}
+}
+
+// Short random ID to find error in logs.
+func newIncidentId() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+
+	return strings.ToUpper(hex.EncodeToString(b))
+}
+
+// Renders built-in error page, inline styles need CSP nonce.
+func builtInErrorPage(page *ErrorPage, nonce string) string {
+	var sb strings.Builder
+
+	sb.WriteString("<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\">")
+	fmt.Fprintf(&sb, "<title>%d %s</title>", page.Status, html.EscapeString(page.Title))
+	fmt.Fprintf(&sb, "<style nonce=\"%s\">body { font-family: sans-serif; margin: 5%% 10%%; } "+
+		"pre { background: #f4f4f4; padding: 1em; overflow: auto; }</style></head><body>", nonce)
+	fmt.Fprintf(&sb, "<h1>%d %s</h1><p>%s</p>", page.Status, html.EscapeString(page.Title), html.EscapeString(page.Message))
+
+	if page.IncidentId != "" {
+		fmt.Fprintf(&sb, "<p>Incident ID: <code>%s</code></p>", page.IncidentId)
+	}
+
+	if page.Details != "" {
+		fmt.Fprintf(&sb, "<pre>%s</pre>", html.EscapeString(page.Details))
+	}
+
+	sb.WriteString("</body></html>\n")
+
+	return sb.String()
+}
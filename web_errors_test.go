@@ -0,0 +1,183 @@
+package goapp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gin-gonic/gin"
+)
+
+type testErrorReporter struct {
+	reports []*ErrorReport
+}
+
+func (r *testErrorReporter) ReportError(ctx context.Context, report *ErrorReport) {
+	r.reports = append(r.reports, report)
+}
+
+func newErrorsTestApp(version string) (*AppBase, *testErrorReporter) {
+	reporter := &testErrorReporter{}
+
+	app := NewAppBase(&testAppSettings{})
+	app.Version = version
+	app.baseSettings.WebserverCookieSecret = "test"
+	app.baseSettings.WebApiCsrfDisabled = true
+	app.WebApiPathPrefix = "/api"
+	app.ErrorReporter = reporter
+
+	app.ApiHandler("/fail", func(r *ApiRequest) error { return errors.New("secret database error") })
+
+	app.BuildWebRouterF = func(r *gin.Engine) {
+		r.GET("/panic", func(c *gin.Context) { panic("boom") })
+		r.GET("/forbidden", func(c *gin.Context) { app.ErrorReply(c, http.StatusForbidden, errors.New("no access")) })
+	}
+	app.buildWebRouter()
+
+	return app, reporter
+}
+
+func errorsTestCall(app *AppBase, method, path, accept string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, path, nil)
+	request.Header.Set("Accept", accept)
+
+	w := httptest.NewRecorder()
+	app.webRouter.ServeHTTP(w, request)
+
+	return w
+}
+
+func TestErrorPagesDev(t *testing.T) {
+	app, reporter := newErrorsTestApp(DEV_MODE_LABEL)
+
+	w := errorsTestCall(app, http.MethodGet, "/no-such-page", "text/html")
+	if w.Code != 404 || !strings.Contains(w.Body.String(), "<h1>404 Not Found</h1>") {
+		t.Errorf("unexpected 404 page: %d %s", w.Code, w.Body.String())
+	}
+
+	w = errorsTestCall(app, http.MethodGet, "/no-such-page", "application/json")
+	if w.Code != 404 || !strings.Contains(w.Body.String(), `"status":"error"`) {
+		t.Errorf("unexpected 404 JSON: %d %s", w.Code, w.Body.String())
+	}
+
+	w = errorsTestCall(app, http.MethodGet, "/forbidden", "text/html")
+	if w.Code != 403 || !strings.Contains(w.Body.String(), "no access") {
+		t.Errorf("unexpected 403 page: %d %s", w.Code, w.Body.String())
+	}
+
+	// detailed page with stack trace
+	w = errorsTestCall(app, http.MethodGet, "/panic", "text/html")
+	if w.Code != 500 || !strings.Contains(w.Body.String(), "panic: boom") || !strings.Contains(w.Body.String(), "goroutine ") {
+		t.Errorf("unexpected panic page: %d %s", w.Code, w.Body.String())
+	}
+
+	if len(reporter.reports) != 1 || reporter.reports[0].Panic != "boom" || reporter.reports[0].Stack == nil || reporter.reports[0].RequestId == "" {
+		t.Errorf("panic is not reported: %+v", reporter.reports)
+	}
+
+	// error message is shown in DEV mode
+	w = errorsTestCall(app, http.MethodPost, "/api/fail", "")
+	if w.Code != 500 || !strings.Contains(w.Body.String(), "secret database error") {
+		t.Errorf("unexpected API error reply: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestErrorPagesProduction(t *testing.T) {
+	app, reporter := newErrorsTestApp("1.0.0")
+
+	w := errorsTestCall(app, http.MethodGet, "/panic", "text/html")
+	if w.Code != 500 || strings.Contains(w.Body.String(), "boom") || strings.Contains(w.Body.String(), "goroutine ") {
+		t.Errorf("production page should not have details: %d %s", w.Code, w.Body.String())
+	}
+
+	if len(reporter.reports) != 1 || !strings.Contains(w.Body.String(), reporter.reports[0].IncidentId) {
+		t.Errorf("production page should have incident ID: %s", w.Body.String())
+	}
+
+	// strict CSP blocks style attributes, styles are allowed by nonce
+	if csp := w.Header().Get("Content-Security-Policy"); strings.Contains(w.Body.String(), "style=\"") ||
+		!strings.Contains(csp, "'nonce-"+cspNonceFromPage(w.Body.String())+"'") {
+		t.Errorf("page styles should be allowed by CSP nonce: %s %s", csp, w.Body.String())
+	}
+
+	w = errorsTestCall(app, http.MethodPost, "/api/fail", "")
+
+	var out map[string]any
+	json.Unmarshal(w.Body.Bytes(), &out)
+
+	if w.Code != 500 || strings.Contains(w.Body.String(), "secret") || out["incident_id"] != reporter.reports[1].IncidentId {
+		t.Errorf("unexpected API error reply: %d %s", w.Code, w.Body.String())
+	}
+
+	if reporter.reports[1].Err.Error() != "secret database error" || reporter.reports[1].Request.URL.Path != "/api/fail" {
+		t.Errorf("API error is not reported: %+v", reporter.reports[1])
+	}
+}
+
+func TestErrorPageTemplates(t *testing.T) {
+	app := NewAppBase(&testAppSettings{})
+	app.UseTemplates(fstest.MapFS{
+		"layouts/base.html":       {Data: []byte(`<main>{{template "content" .}}</main>`)},
+		"pages/errors/error.html": {Data: []byte(`{{.Data.Status}}: {{.Data.Message}}`)},
+	}, nil)
+
+	if err := app.loadTemplates(); err != nil {
+		t.Fatal(err)
+	}
+
+	app.buildWebRouter()
+
+	w := errorsTestCall(app, http.MethodGet, "/no-such-page", "text/html")
+	if w.Code != 404 || w.Body.String() != "<main>404: The page you are looking for does not exist.</main>" {
+		t.Errorf("unexpected templated 404 page: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestErrorReporting(t *testing.T) {
+	app, reporter := newErrorsTestApp("1.0.0")
+
+	app.webRouter.GET("/own-500", func(c *gin.Context) { c.JSON(http.StatusInternalServerError, gin.H{"status": "error"}) })
+	app.webRouter.GET("/own-503", func(c *gin.Context) { c.AbortWithStatus(http.StatusServiceUnavailable) })
+
+	call := func(method, path, body string) int {
+		w := httptest.NewRecorder()
+		app.webRouter.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+
+		return w.Code
+	}
+
+	// API client errors are not reported
+	if code := call(http.MethodPost, "/api/unknown", ""); code != http.StatusNotFound {
+		t.Errorf("unknown API path: expected 404, got %d", code)
+	}
+
+	if code := call(http.MethodPost, "/api/fail", "[1, 2]"); code != http.StatusBadRequest {
+		t.Errorf("unparseable API request body: expected 400, got %d", code)
+	}
+
+	if len(reporter.reports) != 0 {
+		t.Errorf("client errors should not be reported: %+v", reporter.reports)
+	}
+
+	// 5xx replies written by handlers themselves are reported once
+	for path, method := range map[string]string{"/own-500": http.MethodGet, "/own-503": http.MethodGet, "/api/fail": http.MethodPost} {
+		reporter.reports = nil
+
+		if code := call(method, path, ""); code < 500 || len(reporter.reports) != 1 || reporter.reports[0].Status != code {
+			t.Errorf("%s: expected single report for %d reply, got %+v", path, code, reporter.reports)
+		}
+	}
+
+	// readiness 503 is not an error
+	reporter.reports = nil
+	app.draining.Store(true)
+
+	if code := call(http.MethodGet, "/readyz", ""); code != http.StatusServiceUnavailable || len(reporter.reports) != 0 {
+		t.Errorf("readiness failure should not be reported: %d %+v", code, reporter.reports)
+	}
+}
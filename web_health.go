@@ -0,0 +1,244 @@
+package goapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Health check function. Should return nil if everything is fine and respect ctx deadline.
+type HealthCheckFunc func(ctx context.Context) error
+
+// Options for AppBase.AddHealthCheck()
+type HealthCheckOptions struct {
+	Timeout  time.Duration // check is failed if it runs longer, 2 seconds by default
+	CacheTtl time.Duration // result is cached for this time, 5 seconds by default
+	Liveness bool          // check is used for liveness endpoint too (readiness only by default)
+}
+
+// Health check returns it if it has nothing to check (feature is not used by application).
+var ErrHealthCheckSkipped = errors.New("skipped")
+
+// worker pool is reported saturated when its queue is filled more than this
+const workerPoolSaturationThreshold = 0.9
+
+type healthCheck struct {
+	name    string
+	check   HealthCheckFunc
+	options HealthCheckOptions
+
+	mutex     sync.Mutex
+	result    *healthCheckResult
+	checkedAt time.Time
+}
+
+// Health check result in endpoints JSON output
+type healthCheckResult struct {
+	Status     string    `json:"status"` // ok, fail or skipped
+	Error      string    `json:"error,omitempty"`
+	DurationMs float64   `json:"duration_ms"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+// Registers health check used by readiness endpoint (and liveness one if options.Liveness set).
+// Options can be nil.
+func (app *AppBase) AddHealthCheck(name string, check HealthCheckFunc, options *HealthCheckOptions) *AppBase {
+	for _, hc := range app.healthChecks {
+		if hc.name == name {
+			log.Panicf("health check '%s' already registered", name)
+		}
+	}
+
+	hc := &healthCheck{name: name, check: check}
+
+	if options != nil {
+		hc.options = *options
+	}
+
+	if hc.options.Timeout <= 0 {
+		hc.options.Timeout = 2 * time.Second
+	}
+
+	if hc.options.CacheTtl <= 0 {
+		hc.options.CacheTtl = 5 * time.Second
+	}
+
+	app.healthChecks = append(app.healthChecks, hc)
+
+	return app //for method chaining
+}
+
+// Built-in checks
+func (app *AppBase) addBuiltInHealthChecks() {
+	app.AddHealthCheck("database", app.databaseHealthCheck, nil)
+	app.AddHealthCheck("worker_pools", app.workerPoolsHealthCheck, nil)
+}
+
+// Pings database if it is opened.
+func (app *AppBase) databaseHealthCheck(ctx context.Context) error {
+	if app.Database == nil || app.Database.Db() == nil {
+		return ErrHealthCheckSkipped
+	}
+
+	sqlDb, err := app.Database.Db().DB()
+	if err != nil {
+		return err
+	}
+
+	return sqlDb.PingContext(ctx)
+}
+
+// Checks that worker pools started with AppBase.StartWorkerPool() have room in their queues.
+func (app *AppBase) workerPoolsHealthCheck(ctx context.Context) error {
+	app.workerPoolsMutex.Lock()
+	defer app.workerPoolsMutex.Unlock()
+
+	if len(app.workerPools) == 0 {
+		return ErrHealthCheckSkipped
+	}
+
+	for _, wp := range app.workerPools {
+		if usage := wp.QueueUsage(); usage > workerPoolSaturationThreshold {
+			return fmt.Errorf("worker pool %s queue is %.0f%% full", wp.name, usage*100)
+		}
+	}
+
+	return nil
+}
+
+// Returns cached result or runs check.
+func (hc *healthCheck) run(ctx context.Context, logger *slog.Logger) *healthCheckResult {
+	hc.mutex.Lock()
+	defer hc.mutex.Unlock()
+
+	if hc.result != nil && time.Since(hc.checkedAt) < hc.options.CacheTtl {
+		return hc.result
+	}
+
+	// result is cached and shared by requests, so canceled request should not make check fail
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), hc.options.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- fmt.Errorf("panic: %v", p)
+			}
+		}()
+
+		done <- hc.check(ctx)
+	}()
+
+	var err error
+
+	// check function can ignore ctx, do not wait for it longer than timeout
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("timeout after %s", hc.options.Timeout)
+	}
+
+	result := &healthCheckResult{
+		Status:     "ok",
+		DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+		CheckedAt:  start,
+	}
+
+	if errors.Is(err, ErrHealthCheckSkipped) {
+		result.Status = "skipped"
+	} else if err != nil {
+		result.Status = "fail"
+		result.Error = err.Error()
+
+		logger.Warn("Health check failed", "check", hc.name, "error", err)
+	}
+
+	hc.result = result
+	hc.checkedAt = time.Now()
+
+	return result
+}
+
+// Registers health endpoint unless path is empty or has route already.
+func (app *AppBase) addHealthRoute(path string, livenessOnly bool) {
+	if path == "" {
+		return
+	}
+
+	for _, route := range app.webRouter.Routes() {
+		if route.Path == path && route.Method == http.MethodGet {
+			return
+		}
+	}
+
+	handler := func(c *gin.Context) { app.healthReply(c, livenessOnly) }
+
+	app.webRouter.GET(path, handler)
+	app.webRouter.HEAD(path, handler)
+}
+
+// Runs checks in parallel and writes JSON reply: 200 if all checks passed, 503 otherwise.
+func (app *AppBase) healthReply(c *gin.Context, livenessOnly bool) {
+	// failed checks are logged already, 503 is expected here
+	c.Set(errorReportedGinKey, true)
+
+	// readiness turns false as soon as shutdown begins
+	if !livenessOnly && app.BaseContext != nil && app.BaseContext.Err() != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "shutting_down"})
+		return
+	}
+
+	// `ctl drain` was called
+	if !livenessOnly && app.IsDraining() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "draining"})
+		return
+	}
+
+	results := make(map[string]*healthCheckResult)
+
+	var (
+		wg    sync.WaitGroup
+		mutex sync.Mutex
+	)
+
+	for _, hc := range app.healthChecks {
+		if livenessOnly && !hc.options.Liveness {
+			continue
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			result := hc.run(c.Request.Context(), app.componentLogger(LogComponentApp))
+
+			mutex.Lock()
+			results[hc.name] = result
+			mutex.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	status, code := "ok", http.StatusOK
+
+	for _, result := range results {
+		if result.Status == "fail" {
+			status, code = "fail", http.StatusServiceUnavailable
+		}
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(code, gin.H{"status": status, "checks": results})
+}
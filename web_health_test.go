@@ -0,0 +1,101 @@
+package goapp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHealthEndpoints(t *testing.T) {
+	app := NewAppBase(&testAppSettings{})
+	app.BaseContext = context.Background()
+	app.initBaseContext()
+
+	var calls atomic.Int32
+	var failing atomic.Bool
+
+	app.AddHealthCheck("cache", func(ctx context.Context) error {
+		calls.Add(1)
+
+		if failing.Load() {
+			return errors.New("cache is down")
+		}
+
+		return nil
+	}, &HealthCheckOptions{CacheTtl: time.Hour})
+
+	app.AddHealthCheck("slow", func(ctx context.Context) error {
+		time.Sleep(time.Second) // ignores ctx
+		return nil
+	}, &HealthCheckOptions{Timeout: 10 * time.Millisecond, Liveness: true})
+
+	app.buildWebRouter()
+
+	get := func(path string) (int, map[string]any) {
+		w := httptest.NewRecorder()
+		app.webRouter.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+		var out map[string]any
+		json.Unmarshal(w.Body.Bytes(), &out)
+
+		return w.Code, out
+	}
+
+	code, out := get("/readyz")
+	checks, _ := out["checks"].(map[string]any)
+
+	if code != http.StatusServiceUnavailable || len(checks) != 4 {
+		t.Fatalf("unexpected readiness reply: %d %v", code, out)
+	}
+
+	if slow := checks["slow"].(map[string]any); slow["status"] != "fail" || slow["error"] != "timeout after 10ms" {
+		t.Errorf("slow check should time out: %v", slow)
+	}
+
+	for name, expected := range map[string]string{"cache": "ok", "database": "skipped", "worker_pools": "skipped"} {
+		if status := checks[name].(map[string]any)["status"]; status != expected {
+			t.Errorf("check %s: expected %s, got %v", name, expected, status)
+		}
+	}
+
+	// cached result is used
+	failing.Store(true)
+	get("/readyz")
+
+	if calls.Load() != 1 {
+		t.Errorf("check result is not cached, %d calls", calls.Load())
+	}
+
+	// liveness uses liveness checks only
+	if code, out := get("/healthz"); code != http.StatusServiceUnavailable || len(out["checks"].(map[string]any)) != 1 {
+		t.Errorf("unexpected liveness reply: %d %v", code, out)
+	}
+
+	app.cancelBase()
+
+	if code, out := get("/readyz"); code != http.StatusServiceUnavailable || out["status"] != "shutting_down" {
+		t.Errorf("readiness should fail on shutdown: %d %v", code, out)
+	}
+}
+
+func TestHealthCheckCanceledRequest(t *testing.T) {
+	hc := &healthCheck{
+		name:    "db",
+		check:   func(ctx context.Context) error { return ctx.Err() },
+		options: HealthCheckOptions{Timeout: time.Second, CacheTtl: time.Hour},
+	}
+
+	// client went away, result is still cached for everyone else
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if result := hc.run(ctx, slog.Default()); result.Status != "ok" {
+		t.Errorf("canceled request should not fail check: %+v", result)
+	}
+}
@@ -0,0 +1,200 @@
+package goapp
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Returns webserver listen addresses (tcp://host:port or unix:///path). Settings webserver_hostname
+// and webserver_port are used if webserver_listen list is empty.
+func (app *AppBase) listenAddresses() []string {
+	if len(app.baseSettings.WebserverListen) > 0 {
+		return app.baseSettings.WebserverListen
+	}
+
+	return []string{
+		"tcp://" + net.JoinHostPort(
+			app.baseSettings.WebserverHostname,
+			strconv.FormatUint(uint64(app.baseSettings.WebserverPort), 10),
+		),
+	}
+}
+
+// Splits listen address to network and address for net.Listen(). Address without scheme is
+// considered to be TCP one.
+func parseListenAddress(listenAddress string) (network, address string, err error) {
+	scheme, rest, found := strings.Cut(listenAddress, "://")
+
+	if !found {
+		return "tcp", listenAddress, nil
+	}
+
+	switch scheme {
+	case "tcp", "tcp4", "tcp6":
+		if _, _, err := net.SplitHostPort(rest); err != nil {
+			return "", "", fmt.Errorf("invalid listen address '%s': %w", listenAddress, err)
+		}
+
+		return scheme, rest, nil
+	case "unix":
+		if rest == "" {
+			return "", "", fmt.Errorf("invalid listen address '%s': empty socket path", listenAddress)
+		}
+
+		return "unix", rest, nil
+	default:
+		return "", "", fmt.Errorf("invalid listen address '%s': unknown scheme '%s'", listenAddress, scheme)
+	}
+}
+
+// Opens all webserver listeners. Already opened ones are closed if some listener fails.
+func (app *AppBase) openListeners() (list []net.Listener, err error) {
+	defer func() {
+		if err != nil {
+			for _, l := range list {
+				l.Close()
+			}
+
+			list = nil
+		}
+	}()
+
+	for _, listenAddress := range app.listenAddresses() {
+		network, address, err := parseListenAddress(listenAddress)
+		if err != nil {
+			return list, err
+		}
+
+		var l net.Listener
+
+		if network == "unix" {
+			l, err = app.listenUnixSocket(address)
+		} else {
+			l, err = net.Listen(network, address)
+		}
+
+		if err != nil {
+			return list, err
+		}
+
+		list = append(list, l)
+	}
+
+	return list, nil
+}
+
+// Listens on unix socket. Stale socket file is removed, permissions and owner are set from settings.
+func (app *AppBase) listenUnixSocket(path string) (net.Listener, error) {
+	if err := removeStaleSocket(path, app.componentLogger(LogComponentWeb)); err != nil {
+		return nil, err
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	if app.baseSettings.WebserverSocketMode != "" {
+		mode, err := strconv.ParseUint(app.baseSettings.WebserverSocketMode, 8, 32)
+		if err != nil {
+			l.Close()
+			return nil, fmt.Errorf("invalid webserver_socket_mode '%s': %w", app.baseSettings.WebserverSocketMode, err)
+		}
+
+		if err := os.Chmod(path, os.FileMode(mode)); err != nil {
+			l.Close()
+			return nil, err
+		}
+	}
+
+	if app.baseSettings.WebserverSocketOwner != "" {
+		uid, gid, err := lookupOwner(app.baseSettings.WebserverSocketOwner)
+		if err != nil {
+			l.Close()
+			return nil, err
+		}
+
+		if err := os.Chown(path, uid, gid); err != nil {
+			l.Close()
+			return nil, err
+		}
+	}
+
+	return l, nil
+}
+
+// Removes socket file left by crashed process. Returns error if socket is in use or path is
+// not a socket.
+func removeStaleSocket(path string, logger *slog.Logger) error {
+	info, err := os.Stat(path)
+
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return fmt.Errorf("socket %s is in use by another process", path)
+	}
+
+	logger.Info("Removing stale socket file", "path", path)
+
+	return os.Remove(path)
+}
+
+// Parses "user:group" or "user" (user's primary group is used then) to numeric IDs.
+func lookupOwner(owner string) (uid, gid int, err error) {
+	userName, groupName, _ := strings.Cut(owner, ":")
+
+	u, err := user.Lookup(userName)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	gidString := u.Gid
+
+	if groupName != "" {
+		g, err := user.LookupGroup(groupName)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		gidString = g.Gid
+	}
+
+	if uid, err = strconv.Atoi(u.Uid); err != nil {
+		return 0, 0, err
+	}
+
+	if gid, err = strconv.Atoi(gidString); err != nil {
+		return 0, 0, err
+	}
+
+	return uid, gid, nil
+}
+
+// Returns human readable URL for listener.
+func listenerUrl(l net.Listener, tls bool) string {
+	if l.Addr().Network() == "unix" {
+		return "unix://" + l.Addr().String()
+	}
+
+	if tls {
+		return "https://" + l.Addr().String()
+	}
+
+	return "http://" + l.Addr().String()
+}
@@ -0,0 +1,69 @@
+package goapp
+
+import (
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseListenAddress(t *testing.T) {
+	cases := map[string][2]string{
+		"tcp://127.0.0.1:8080": {"tcp", "127.0.0.1:8080"},
+		"tcp6://[::1]:8080":    {"tcp6", "[::1]:8080"},
+		"unix:///run/app.sock": {"unix", "/run/app.sock"},
+		"localhost:8080":       {"tcp", "localhost:8080"},
+	}
+
+	for input, expected := range cases {
+		network, address, err := parseListenAddress(input)
+		if err != nil || network != expected[0] || address != expected[1] {
+			t.Errorf("%s: unexpected result %s %s %v", input, network, address, err)
+		}
+	}
+
+	for _, input := range []string{"udp://localhost:80", "tcp://localhost", "unix://"} {
+		if _, _, err := parseListenAddress(input); err == nil {
+			t.Errorf("%s: error expected", input)
+		}
+	}
+}
+
+func TestUnixSocketListener(t *testing.T) {
+	dir, err := os.MkdirTemp("", "goapp") // t.TempDir() path can be too long for unix socket
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.sock")
+	app := &AppBase{baseSettings: &AppSettingsBase{WebserverSocketMode: "0600"}, logger: slog.Default()}
+
+	//stale socket file left by "crashed" process
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	l, err := app.listenUnixSocket(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("unexpected socket file mode: %v %v", info.Mode(), err)
+	}
+
+	if _, err := app.listenUnixSocket(path); err == nil {
+		t.Error("error expected for socket in use")
+	}
+
+	l.Close()
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("socket file was not removed on close")
+	}
+}
@@ -0,0 +1,149 @@
+package goapp
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Admin API endpoint to get (GET) or switch (POST) maintenance mode
+const maintenanceApiPath = "/admin/maintenance"
+
+const defaultMaintenanceMessage = "Service is under maintenance. Please try again later."
+
+// Tells if client expects JSON reply: web API requests and requests accepting JSON but not HTML.
+func (app *AppBase) isJsonRequest(c *gin.Context) bool {
+	path := c.Request.URL.Path
+
+	if app.WebApiPathPrefix != "" && (path == app.WebApiPathPrefix || strings.HasPrefix(path, app.WebApiPathPrefix+"/")) {
+		return true
+	}
+
+	accept := c.GetHeader("Accept")
+
+	return strings.Contains(accept, "application/json") && !strings.Contains(accept, "text/html")
+}
+
+// gin middleware replying 503 while maintenance mode is on.
+func (app *AppBase) maintenanceMiddleware(c *gin.Context) {
+	state := app.Maintenance()
+
+	if state == nil || app.maintenanceBypass(c) {
+		return
+	}
+
+	retryAfter := state.RetryAfter
+	if retryAfter <= 0 {
+		retryAfter = defaultMaintenanceRetryAfter
+	}
+
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.Header("Cache-Control", "no-store")
+	c.Set(errorReportedGinKey, true) // not an error
+
+	if app.MaintenanceReplyF != nil {
+		app.MaintenanceReplyF(c, state)
+		c.AbortWithStatus(http.StatusServiceUnavailable) // does nothing if reply is written already
+		return
+	}
+
+	message := state.Message
+	if message == "" {
+		message = defaultMaintenanceMessage
+	}
+
+	if app.isJsonRequest(c) {
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"status":      "error",
+			"message":     message,
+			"maintenance": true,
+			"retry_after": retryAfter,
+			"request_id":  RequestId(c),
+		})
+		return
+	}
+
+	// inline style needs CSP nonce
+	page := fmt.Sprintf(
+		"<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\"><title>%s</title>"+
+			"<style nonce=\"%s\">body { font-family: sans-serif; text-align: center; margin-top: 10%%; }</style></head>"+
+			"<body><h1>%s</h1><p>%s</p></body></html>\n",
+		html.EscapeString(app.AppName), CspNonce(c), html.EscapeString(app.AppName), html.EscapeString(message),
+	)
+
+	c.Data(http.StatusServiceUnavailable, "text/html; charset=utf-8", []byte(page))
+	c.Abort()
+}
+
+// Health, metrics and admin endpoints, allowlisted IPs and admin sessions are served in maintenance mode.
+func (app *AppBase) maintenanceBypass(c *gin.Context) bool {
+	path := c.Request.URL.Path
+
+	for _, exempt := range []string{app.HealthPath, app.ReadinessPath, app.MetricsPath, maintenanceApiPath} {
+		if exempt != "" && path == exempt {
+			return true
+		}
+	}
+
+	if strings.HasPrefix(path, debugPathPrefix+"/") {
+		return true
+	}
+
+	if len(app.maintenanceAllowNets) > 0 && ipInNets(c.ClientIP(), app.maintenanceAllowNets) {
+		return true
+	}
+
+	return app.IsAdminF != nil && app.IsAdminF(c)
+}
+
+// Registers maintenance admin API on admin listener and on main webserver if admin_token is set.
+func (app *AppBase) addMaintenanceRoutes() {
+	if app.adminRouter != nil {
+		app.adminRouter.GET(maintenanceApiPath, app.adminAuthMiddleware, app.maintenanceGetGinHandler)
+		app.adminRouter.POST(maintenanceApiPath, app.adminAuthMiddleware, app.maintenanceSetGinHandler)
+	}
+
+	if app.baseSettings.AdminToken != "" {
+		auth := bearerTokenMiddleware(app.baseSettings.AdminToken, "admin")
+
+		app.webRouter.GET(maintenanceApiPath, auth, app.maintenanceGetGinHandler)
+		app.webRouter.POST(maintenanceApiPath, auth, app.maintenanceSetGinHandler)
+	}
+}
+
+func (app *AppBase) maintenanceGetGinHandler(c *gin.Context) {
+	state := app.Maintenance()
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{"enabled": state != nil, "state": state})
+}
+
+// Accepts JSON: {"enabled": true, "message": "...", "retry_after": 600}
+func (app *AppBase) maintenanceSetGinHandler(c *gin.Context) {
+	var input struct {
+		Enabled bool `json:"enabled"`
+		MaintenanceState
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	var state *MaintenanceState
+	if input.Enabled {
+		state = &MaintenanceState{Message: input.Message, RetryAfter: input.RetryAfter}
+	}
+
+	if err := app.SetMaintenance(state); err != nil {
+		app.logger.ErrorContext(c.Request.Context(), "Can not switch maintenance mode", "component", LogComponentWeb, "error", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	app.maintenanceGetGinHandler(c)
+}
@@ -0,0 +1,140 @@
+package goapp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMaintenanceMiddleware(t *testing.T) {
+	app := NewAppBase(&testAppSettings{})
+	app.AppSettingsFilename = filepath.Join(t.TempDir(), "settings.yml")
+	app.baseSettings.WebserverCookieSecret = "test"
+	app.baseSettings.WebApiCsrfDisabled = true
+	app.baseSettings.MaintenanceAllowIps = []string{"10.0.0.0/8"}
+	app.baseSettings.AdminListen = "127.0.0.1:0"
+	app.WebApiPathPrefix = "/api"
+	app.IsAdminF = func(c *gin.Context) bool { return c.GetHeader("X-Test-Admin") == "yes" }
+
+	app.ApiHandler("/ok", func(r *ApiRequest) error { return nil })
+
+	app.BuildWebRouterF = func(r *gin.Engine) {
+		r.GET("/page", func(c *gin.Context) { c.String(200, "ok") })
+	}
+	app.buildWebRouter()
+
+	call := func(h http.Handler, method, path string, body string, headers map[string]string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		request.RemoteAddr = "192.0.2.1:5000"
+
+		for name, value := range headers {
+			request.Header.Set(name, value)
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, request)
+
+		return w
+	}
+
+	// switched on with admin API
+	w := call(app.AdminHandler(), http.MethodPost, maintenanceApiPath, `{"enabled": true, "message": "Back <soon>", "retry_after": 60}`, nil)
+	if w.Code != 200 || !strings.Contains(w.Body.String(), `"enabled":true`) {
+		t.Fatalf("unexpected admin API reply: %d %s", w.Code, w.Body.String())
+	}
+
+	w = call(app.webRouter, http.MethodGet, "/page", "", map[string]string{"Accept": "text/html"})
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "60" || !strings.Contains(w.Body.String(), "Back &lt;soon&gt;") {
+		t.Errorf("unexpected HTML reply: %d %s %s", w.Code, w.Header().Get("Retry-After"), w.Body.String())
+	}
+
+	if csp := w.Header().Get("Content-Security-Policy-Report-Only"); !strings.Contains(w.Body.String(), "<style nonce=") ||
+		strings.Contains(w.Body.String(), "style=\"") || !strings.Contains(csp, "'nonce-"+cspNonceFromPage(w.Body.String())+"'") {
+		t.Errorf("page styles should be allowed by CSP nonce: %s %s", csp, w.Body.String())
+	}
+
+	w = call(app.webRouter, http.MethodPost, "/api/ok", "", nil)
+
+	var out map[string]any
+	json.Unmarshal(w.Body.Bytes(), &out)
+
+	if w.Code != http.StatusServiceUnavailable || out["maintenance"] != true || out["message"] != "Back <soon>" {
+		t.Errorf("unexpected JSON reply: %d %s", w.Code, w.Body.String())
+	}
+
+	// exempt requests
+	for name, w := range map[string]*httptest.ResponseRecorder{
+		"health":        call(app.webRouter, http.MethodGet, "/healthz", "", nil),
+		"admin session": call(app.webRouter, http.MethodGet, "/page", "", map[string]string{"X-Test-Admin": "yes"}),
+	} {
+		if w.Code != 200 {
+			t.Errorf("%s: request should be served, got %d", name, w.Code)
+		}
+	}
+
+	// no proxies trusted, header is ignored
+	if w := call(app.webRouter, http.MethodGet, "/page", "", map[string]string{"X-Forwarded-For": "10.0.0.1"}); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("spoofed client IP should not be allowed, got %d", w.Code)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/page", nil)
+	request.RemoteAddr = "10.1.2.3:5000"
+
+	w = httptest.NewRecorder()
+	app.webRouter.ServeHTTP(w, request)
+
+	if w.Code != 200 {
+		t.Errorf("allowlisted IP should be served, got %d", w.Code)
+	}
+
+	// switched off
+	call(app.AdminHandler(), http.MethodPost, maintenanceApiPath, `{"enabled": false}`, nil)
+
+	if w := call(app.webRouter, http.MethodGet, "/page", "", nil); w.Code != 200 {
+		t.Errorf("maintenance mode should be off, got %d", w.Code)
+	}
+}
+
+func TestMaintenanceAdminAuth(t *testing.T) {
+	app := NewAppBase(&testAppSettings{})
+	app.AppSettingsFilename = filepath.Join(t.TempDir(), "settings.yml")
+
+	call := func(token string) int {
+		request := httptest.NewRequest(http.MethodPost, maintenanceApiPath, strings.NewReader(`{"enabled": false}`))
+		if token != "" {
+			request.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		w := httptest.NewRecorder()
+		app.AdminHandler().ServeHTTP(w, request)
+
+		return w.Code
+	}
+
+	// admin_token is required on admin listener when it is set
+	app.baseSettings.AdminListen = "127.0.0.1:0"
+	app.baseSettings.AdminToken = "secret"
+	app.buildWebRouter()
+
+	if code := call(""); code != http.StatusUnauthorized {
+		t.Errorf("request without token: expected 401, got %d", code)
+	}
+
+	if code := call("secret"); code != 200 {
+		t.Errorf("request with token: expected 200, got %d", code)
+	}
+
+	// no admin_token on non-loopback listener
+	app.baseSettings.AdminListen = "0.0.0.0:0"
+	app.baseSettings.AdminToken = ""
+	app.buildWebRouter()
+
+	if code := call(""); code != http.StatusForbidden {
+		t.Errorf("non-loopback listener without token: expected 403, got %d", code)
+	}
+}
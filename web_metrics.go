@@ -0,0 +1,201 @@
+package goapp
+
+import (
+	"net/http"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// gin context key marking API request failed (error reply or "error" status)
+const apiErrorGinKey = "goapp.api_error"
+
+// route label for requests without registered route (static files, 404)
+const metricsUnmatchedRoute = "unmatched"
+
+// Built-in HTTP metrics
+type httpMetrics struct {
+	requests        *Counter
+	requestDuration *Histogram
+	apiRequests     *Counter
+	apiDuration     *Histogram
+	apiErrors       *Counter
+}
+
+// methods used as is in metric labels, others are counted as OTHER
+var metricsMethods = map[string]bool{
+	http.MethodGet: true, http.MethodHead: true, http.MethodPost: true, http.MethodPut: true,
+	http.MethodPatch: true, http.MethodDelete: true, http.MethodOptions: true,
+}
+
+var processStartTime = time.Now()
+
+// Returns metrics registry to add application counters and gauges:
+//
+//	ordersTotal := app.Metrics().Counter("myapp_orders_total", "Orders placed", "payment")
+//	ordersTotal.Inc("card")
+//
+// Metrics are served at MetricsPath on admin listener (admin_listen setting) and on main webserver
+// if metrics_token setting is set.
+func (app *AppBase) Metrics() *MetricsRegistry {
+	return app.metrics
+}
+
+// Registers built-in HTTP, worker pools, database and Go runtime metrics.
+func (app *AppBase) registerBuiltInMetrics() {
+	r := app.metrics
+
+	app.httpMetrics = &httpMetrics{
+		requests:        r.Counter("goapp_http_requests_total", "HTTP requests by route and status", "method", "route", "status"),
+		requestDuration: r.Histogram("goapp_http_request_duration_seconds", "HTTP request durations by route", nil, "method", "route"),
+		apiRequests:     r.Counter("goapp_api_requests_total", "Web API requests by path and status", "path", "status"),
+		apiDuration:     r.Histogram("goapp_api_request_duration_seconds", "Web API request durations by path", nil, "path"),
+		apiErrors:       r.Counter("goapp_api_errors_total", "Web API requests failed", "path"),
+	}
+
+	poolMetric := func(name, help, kind string, value func(s WorkerPoolStats) float64) *metricCollector {
+		return newMetricCollector(name, help, kind, []string{"pool"}, func() []metricSample {
+			app.workerPoolsMutex.Lock()
+			defer app.workerPoolsMutex.Unlock()
+
+			samples := make([]metricSample, 0, len(app.workerPools))
+			for _, wp := range app.workerPools {
+				samples = append(samples, metricSample{labelValues: []string{wp.name}, value: value(wp.Stats())})
+			}
+
+			return samples
+		})
+	}
+
+	for _, c := range []*metricCollector{
+		poolMetric("goapp_worker_pool_queue_depth", "Jobs waiting in worker pool queue", "gauge", func(s WorkerPoolStats) float64 { return float64(s.Waiting) }),
+		poolMetric("goapp_worker_pool_running_jobs", "Jobs being done by worker pool", "gauge", func(s WorkerPoolStats) float64 { return float64(s.Running) }),
+		poolMetric("goapp_worker_pool_workers", "Worker pool running workers", "gauge", func(s WorkerPoolStats) float64 { return float64(s.Workers) }),
+		poolMetric("goapp_worker_pool_completed_jobs_total", "Jobs done by worker pool", "counter", func(s WorkerPoolStats) float64 { return float64(s.Completed) }),
+	} {
+		r.register(c, c.name)
+	}
+
+	r.register(&metricCollectorGroup{collect: app.databaseMetrics},
+		"goapp_db_max_open_connections", "goapp_db_open_connections", "goapp_db_in_use_connections", "goapp_db_idle_connections",
+		"goapp_db_wait_count_total", "goapp_db_wait_duration_seconds_total", "goapp_db_max_idle_closed_total", "goapp_db_max_lifetime_closed_total",
+	)
+
+	r.register(&metricCollectorGroup{collect: app.runtimeMetrics},
+		"goapp_build_info", "process_start_time_seconds", "go_goroutines", "go_memstats_alloc_bytes", "go_memstats_sys_bytes",
+		"go_memstats_heap_inuse_bytes", "go_memstats_heap_objects", "go_gc_cycles_total", "go_gc_pause_seconds_total",
+	)
+}
+
+// Single sample metric.
+func constMetric(name, help, kind string, value float64) *metricCollector {
+	return newMetricCollector(name, help, kind, nil, func() []metricSample {
+		return []metricSample{{value: value}}
+	})
+}
+
+// sql.DBStats of opened database.
+func (app *AppBase) databaseMetrics() []*metricCollector {
+	if app.Database == nil || app.Database.Db() == nil {
+		return nil
+	}
+
+	sqlDb, err := app.Database.Db().DB()
+	if err != nil {
+		return nil
+	}
+
+	s := sqlDb.Stats()
+
+	return []*metricCollector{
+		constMetric("goapp_db_max_open_connections", "Maximum number of open database connections", "gauge", float64(s.MaxOpenConnections)),
+		constMetric("goapp_db_open_connections", "Open database connections", "gauge", float64(s.OpenConnections)),
+		constMetric("goapp_db_in_use_connections", "Database connections in use", "gauge", float64(s.InUse)),
+		constMetric("goapp_db_idle_connections", "Idle database connections", "gauge", float64(s.Idle)),
+		constMetric("goapp_db_wait_count_total", "Waits for database connection", "counter", float64(s.WaitCount)),
+		constMetric("goapp_db_wait_duration_seconds_total", "Time spent waiting for database connection", "counter", s.WaitDuration.Seconds()),
+		constMetric("goapp_db_max_idle_closed_total", "Database connections closed due to max idle limit", "counter", float64(s.MaxIdleClosed)),
+		constMetric("goapp_db_max_lifetime_closed_total", "Database connections closed due to max lifetime", "counter", float64(s.MaxLifetimeClosed)),
+	}
+}
+
+// Go runtime metrics, memory stats are read once per scrape.
+func (app *AppBase) runtimeMetrics() []*metricCollector {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	buildInfo := newMetricCollector("goapp_build_info", "Application build information", "gauge", []string{"version", "commit", "go_version"},
+		func() []metricSample {
+			return []metricSample{{labelValues: []string{app.Version, app.BuildCommit, app.BuildWith}, value: 1}}
+		},
+	)
+
+	return []*metricCollector{
+		buildInfo,
+		constMetric("process_start_time_seconds", "Process start time (unix time)", "gauge", float64(processStartTime.Unix())),
+		constMetric("go_goroutines", "Number of goroutines", "gauge", float64(runtime.NumGoroutine())),
+		constMetric("go_memstats_alloc_bytes", "Bytes allocated and still in use", "gauge", float64(ms.Alloc)),
+		constMetric("go_memstats_sys_bytes", "Bytes obtained from system", "gauge", float64(ms.Sys)),
+		constMetric("go_memstats_heap_inuse_bytes", "Heap bytes in use", "gauge", float64(ms.HeapInuse)),
+		constMetric("go_memstats_heap_objects", "Number of allocated heap objects", "gauge", float64(ms.HeapObjects)),
+		constMetric("go_gc_cycles_total", "Completed GC cycles", "counter", float64(ms.NumGC)),
+		constMetric("go_gc_pause_seconds_total", "Total GC stop-the-world pause time", "counter", time.Duration(ms.PauseTotalNs).Seconds()),
+	}
+}
+
+// gin middleware counting requests and their durations per route and API path.
+func (app *AppBase) metricsMiddleware(c *gin.Context) {
+	start := time.Now()
+
+	c.Next()
+
+	duration := time.Since(start).Seconds()
+	status := strconv.Itoa(c.Writer.Status())
+
+	route := c.FullPath()
+	if route == "" {
+		route = metricsUnmatchedRoute
+	}
+
+	method := c.Request.Method
+	if !metricsMethods[method] {
+		method = "OTHER"
+	}
+
+	m := app.httpMetrics
+	m.requests.Inc(method, route, status)
+	m.requestDuration.Observe(duration, method, route)
+
+	if path := c.GetString(apiPathGinKey); path != "" {
+		// do not let clients create unlimited number of series
+		if _, registered := app.webApiHandlerList[path]; !registered {
+			path = metricsUnmatchedRoute
+		}
+
+		m.apiRequests.Inc(path, status)
+		m.apiDuration.Observe(duration, path)
+
+		if c.GetBool(apiErrorGinKey) {
+			m.apiErrors.Inc(path)
+		}
+	}
+}
+
+// Registers metrics endpoint on main webserver if metrics_token is set.
+func (app *AppBase) addMetricsRoute() {
+	if app.MetricsPath == "" || app.baseSettings.MetricsToken == "" {
+		return
+	}
+
+	app.webRouter.GET(app.MetricsPath, bearerTokenMiddleware(app.baseSettings.MetricsToken, "metrics"), app.metricsGinHandler)
+}
+
+func (app *AppBase) metricsGinHandler(c *gin.Context) {
+	c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+
+	app.metrics.WriteTo(c.Writer)
+}
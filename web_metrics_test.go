@@ -0,0 +1,177 @@
+package goapp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMetricsEndpoint(t *testing.T) {
+	app := NewAppBase(&testAppSettings{})
+	app.baseSettings.WebserverCookieSecret = "test"
+	app.baseSettings.WebApiCsrfDisabled = true
+	app.baseSettings.MetricsToken = "secret"
+	app.baseSettings.AdminListen = "127.0.0.1:0"
+	app.WebApiPathPrefix = "/api"
+
+	app.ApiHandler("/ok", func(r *ApiRequest) error { return nil })
+	app.ApiHandler("/fail", func(r *ApiRequest) error { return errors.New("failed") })
+
+	app.BuildWebRouterF = func(r *gin.Engine) {
+		r.GET("/page/:id", func(c *gin.Context) { c.String(200, "ok") })
+		r.GET("/panic", func(c *gin.Context) { panic("test") })
+	}
+	app.buildWebRouter()
+
+	app.Metrics().Counter("myapp_things_total", "Things").Inc()
+
+	for _, request := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/page/1", nil),
+		httptest.NewRequest(http.MethodGet, "/page/2", nil),
+		httptest.NewRequest(http.MethodGet, "/panic", nil),
+		httptest.NewRequest(http.MethodGet, "/no-such-page", nil),
+		httptest.NewRequest(http.MethodPost, "/api/ok", nil),
+		httptest.NewRequest(http.MethodPost, "/api/fail", nil),
+		httptest.NewRequest(http.MethodPost, "/api/random-123", nil),
+	} {
+		app.webRouter.ServeHTTP(httptest.NewRecorder(), request)
+	}
+
+	// token is required on main webserver
+	w := httptest.NewRecorder()
+	app.webRouter.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("metrics served without token: %d", w.Code)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	request.Header.Set("Authorization", "Bearer secret")
+
+	w = httptest.NewRecorder()
+	app.webRouter.ServeHTTP(w, request)
+
+	if w.Code != 200 || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Fatalf("unexpected metrics reply: %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	for _, line := range []string{
+		`goapp_http_requests_total{method="GET",route="/page/:id",status="200"} 2`,
+		`goapp_http_requests_total{method="GET",route="/panic",status="500"} 1`,
+		`goapp_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`goapp_http_request_duration_seconds_count{method="GET",route="/page/:id"} 2`,
+		`goapp_api_requests_total{path="/ok",status="200"} 1`,
+		`goapp_api_requests_total{path="/fail",status="500"} 1`,
+		`goapp_api_requests_total{path="unmatched",status="404"} 1`,
+		`goapp_api_errors_total{path="/fail"} 1`,
+		`myapp_things_total 1`,
+		`# TYPE go_goroutines gauge`,
+	} {
+		if !strings.Contains(w.Body.String(), line+"\n") {
+			t.Errorf("metrics output has no line: %s", line)
+		}
+	}
+
+	if strings.Contains(w.Body.String(), `goapp_api_errors_total{path="/ok"}`) {
+		t.Errorf("successful API request counted as error")
+	}
+
+	// admin listener serves metrics without token
+	w = httptest.NewRecorder()
+	app.AdminHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if w.Code != 200 || !strings.Contains(w.Body.String(), "myapp_things_total 1\n") {
+		t.Errorf("unexpected admin metrics reply: %d", w.Code)
+	}
+}
+
+func TestWorkerPoolMetrics(t *testing.T) {
+	app := NewAppBase(&testAppSettings{})
+
+	wp := app.StartWorkerPool(2)
+	defer wp.Stop()
+
+	wp.DoJobList([]WorkerPoolJob{metricsTestJob{}, metricsTestJob{}, metricsTestJob{}}, true)
+
+	var sb strings.Builder
+	app.Metrics().WriteTo(&sb)
+
+	for _, line := range []string{
+		`goapp_worker_pool_completed_jobs_total{pool="1"} 3`,
+		`goapp_worker_pool_queue_depth{pool="1"} 0`,
+		`goapp_worker_pool_running_jobs{pool="1"} 0`,
+	} {
+		if !strings.Contains(sb.String(), line+"\n") {
+			t.Errorf("metrics output has no line: %s", line)
+		}
+	}
+
+	// named pools, stopped ones are removed
+	mailer := app.StartNamedWorkerPool("mailer", 1)
+	mailer.DoSingleJob(metricsTestJob{}, true)
+
+	sb.Reset()
+	app.Metrics().WriteTo(&sb)
+
+	if !strings.Contains(sb.String(), `goapp_worker_pool_completed_jobs_total{pool="mailer"} 1`+"\n") {
+		t.Errorf("metrics output has no named pool: %s", sb.String())
+	}
+
+	mailer.Stop()
+
+	sb.Reset()
+	app.Metrics().WriteTo(&sb)
+
+	if strings.Contains(sb.String(), `pool="mailer"`) {
+		t.Error("stopped pool is still in metrics output")
+	}
+
+	// name can be used again after pool is stopped
+	app.StartNamedWorkerPool("mailer", 1).Stop()
+}
+
+type metricsTestJob struct{}
+
+func (j metricsTestJob) Do() {}
+
+func TestAdminMetricsAuth(t *testing.T) {
+	app := NewAppBase(&testAppSettings{})
+	app.baseSettings.AdminListen = "192.168.1.10:15116"
+	app.baseSettings.AdminToken = "admin"
+	app.baseSettings.MetricsToken = "metrics"
+	app.buildWebRouter()
+
+	for token, expected := range map[string]int{
+		"":        http.StatusUnauthorized,
+		"wrong":   http.StatusUnauthorized,
+		"admin":   http.StatusOK,
+		"metrics": http.StatusOK,
+	} {
+		request := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if token != "" {
+			request.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		w := httptest.NewRecorder()
+		app.AdminHandler().ServeHTTP(w, request)
+
+		if w.Code != expected {
+			t.Errorf("token '%s': expected %d, got %d", token, expected, w.Code)
+		}
+	}
+
+	// without tokens metrics are served on loopback admin listener only
+	app.baseSettings.AdminToken = ""
+	app.baseSettings.MetricsToken = ""
+
+	w := httptest.NewRecorder()
+	app.AdminHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("non-loopback admin listener without tokens: expected 403, got %d", w.Code)
+	}
+}
@@ -0,0 +1,223 @@
+package goapp
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Returns URL path prefix the application is mounted under (from BaseUrl path like
+// "https://host/tools/myapp"). Empty string if application is served from root.
+func (app *AppBase) PathPrefix() string {
+	u, err := url.Parse(app.baseSettings.BaseUrl)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSuffix(u.Path, "/")
+}
+
+// Path for cookies (session etc).
+func (app *AppBase) cookiePath() string {
+	if prefix := app.PathPrefix(); prefix != "" {
+		return prefix
+	}
+
+	return "/"
+}
+
+// Wraps handler to strip PathPrefix() from request paths. Requests without prefix are served as is,
+// so both proxies passing full path and proxies stripping prefix themselves work.
+func (app *AppBase) stripPathPrefix(h http.Handler) http.Handler {
+	prefix := app.PathPrefix()
+
+	if prefix == "" {
+		return h
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != prefix && !strings.HasPrefix(r.URL.Path, prefix+"/") {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		r2 := r.Clone(r.Context())
+		r2.URL.Path = "/" + strings.TrimLeft(strings.TrimPrefix(r.URL.Path, prefix), "/")
+
+		if r.URL.RawPath != "" {
+			r2.URL.RawPath = "/" + strings.TrimLeft(strings.TrimPrefix(r.URL.RawPath, prefix), "/")
+		}
+
+		// gin uses it for trailing slash redirects
+		r2.Header.Set("X-Forwarded-Prefix", prefix)
+
+		h.ServeHTTP(w, r2)
+	})
+}
+
+// Parses IP addresses or CIDRs list (trusted proxies, allowlists).
+func parseIpNets(list []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(list))
+
+	for _, item := range list {
+		if !strings.Contains(item, "/") {
+			if ip := net.ParseIP(item); ip != nil && ip.To4() != nil {
+				item += "/32"
+			} else {
+				item += "/128"
+			}
+		}
+
+		_, ipNet, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, err
+		}
+
+		nets = append(nets, ipNet)
+	}
+
+	return nets, nil
+}
+
+// Configures gin client IP detection from webserver_trusted_proxies and webserver_forwarded_headers settings.
+func (app *AppBase) configureProxies() {
+	var err error
+
+	if app.trustedProxyNets, err = parseIpNets(app.baseSettings.WebserverTrustedProxies); err != nil {
+		app.logger.Error("Invalid webserver_trusted_proxies, no proxies trusted", "component", LogComponentWeb, "error", err)
+		app.trustedProxyNets = nil
+	}
+
+	// Forwarded header is converted to X-Forwarded-For by forwardedHeadersMiddleware
+	app.webRouter.RemoteIPHeaders = nil
+
+	for _, header := range app.baseSettings.WebserverForwardedHeaders {
+		if strings.EqualFold(header, "Forwarded") {
+			header = "X-Forwarded-For"
+		}
+
+		app.webRouter.RemoteIPHeaders = append(app.webRouter.RemoteIPHeaders, http.CanonicalHeaderKey(header))
+	}
+
+	if len(app.trustedProxyNets) == 0 {
+		app.webRouter.SetTrustedProxies(nil) // RemoteAddr only
+		return
+	}
+
+	nets := make([]string, 0, len(app.trustedProxyNets))
+	for _, ipNet := range app.trustedProxyNets {
+		nets = append(nets, ipNet.String())
+	}
+
+	app.webRouter.SetTrustedProxies(nets)
+}
+
+func (app *AppBase) isTrustedProxy(remoteAddr string) bool {
+	return ipInNets(remoteAddr, app.trustedProxyNets)
+}
+
+// Checks if address (IP or host:port) belongs to any of nets.
+func ipInNets(addr string, nets []*net.IPNet) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, ipNet := range nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// gin middleware applying forwarded headers from trusted proxies: RFC 7239 Forwarded header is
+// converted to X-Forwarded-* ones, X-Forwarded-Host and X-Forwarded-Proto are applied to request.
+func (app *AppBase) forwardedHeadersMiddleware(c *gin.Context) {
+	if len(app.trustedProxyNets) == 0 || !app.isTrustedProxy(c.Request.RemoteAddr) {
+		// do not let clients spoof anything
+		return
+	}
+
+	header := c.Request.Header
+
+	if forwarded := header.Values("Forwarded"); len(forwarded) > 0 && app.usesForwardedHeader() {
+		forList, proto, host := parseForwardedHeader(strings.Join(forwarded, ","))
+
+		if len(forList) > 0 {
+			header.Set("X-Forwarded-For", strings.Join(forList, ", "))
+		}
+
+		if proto != "" {
+			header.Set("X-Forwarded-Proto", proto)
+		}
+
+		if host != "" {
+			header.Set("X-Forwarded-Host", host)
+		}
+	}
+
+	if host := header.Get("X-Forwarded-Host"); host != "" {
+		c.Request.Host = strings.TrimSpace(strings.Split(host, ",")[0])
+	}
+
+	if proto := strings.ToLower(header.Get("X-Forwarded-Proto")); proto == "http" || proto == "https" {
+		c.Request.URL.Scheme = proto
+	}
+}
+
+func (app *AppBase) usesForwardedHeader() bool {
+	for _, header := range app.baseSettings.WebserverForwardedHeaders {
+		if strings.EqualFold(header, "Forwarded") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Parses RFC 7239 Forwarded header value. Returns "for" addresses of all hops and proto and host
+// of first hop.
+func parseForwardedHeader(value string) (forList []string, proto, host string) {
+	for i, element := range strings.Split(value, ",") {
+		for _, pair := range strings.Split(element, ";") {
+			name, v, found := strings.Cut(strings.TrimSpace(pair), "=")
+			if !found {
+				continue
+			}
+
+			v = strings.Trim(v, `"`)
+
+			switch strings.ToLower(name) {
+			case "for":
+				// IPv6 addresses are quoted and bracketed with optional port: "[2001:db8::1]:4711"
+				if strings.HasPrefix(v, "[") {
+					v = strings.TrimPrefix(strings.SplitN(v, "]", 2)[0], "[")
+				} else if h, _, err := net.SplitHostPort(v); err == nil {
+					v = h
+				}
+
+				forList = append(forList, v)
+			case "proto":
+				if i == 0 {
+					proto = v
+				}
+			case "host":
+				if i == 0 {
+					host = v
+				}
+			}
+		}
+	}
+
+	return forList, proto, host
+}
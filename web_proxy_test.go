@@ -0,0 +1,112 @@
+package goapp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseForwardedHeader(t *testing.T) {
+	forList, proto, host := parseForwardedHeader(`for=192.0.2.60;proto=https;host=example.com, for="[2001:db8::1]:4711", for=10.0.0.1:8080`)
+
+	if !slices.Equal(forList, []string{"192.0.2.60", "2001:db8::1", "10.0.0.1"}) || proto != "https" || host != "example.com" {
+		t.Errorf("unexpected result: %v %s %s", forList, proto, host)
+	}
+}
+
+func TestTrustedProxies(t *testing.T) {
+	app := NewAppBase(&testAppSettings{})
+	app.baseSettings.WebserverTrustedProxies = []string{"10.0.0.0/8", "192.168.1.1"}
+
+	var clientIp, host, scheme string
+
+	app.BuildWebRouterF = func(r *gin.Engine) {
+		r.GET("/ip", func(c *gin.Context) {
+			clientIp, host, scheme = c.ClientIP(), c.Request.Host, c.Request.URL.Scheme
+		})
+	}
+	app.buildWebRouter()
+
+	call := func(remoteAddr string, headers map[string]string) {
+		request := httptest.NewRequest(http.MethodGet, "/ip", nil)
+		request.RemoteAddr = remoteAddr
+
+		for name, value := range headers {
+			request.Header.Set(name, value)
+		}
+
+		clientIp, host, scheme = "", "", ""
+		app.webRouter.ServeHTTP(httptest.NewRecorder(), request)
+	}
+
+	call("10.1.2.3:5000", map[string]string{"X-Forwarded-For": "203.0.113.7", "X-Forwarded-Host": "example.com", "X-Forwarded-Proto": "https"})
+	if clientIp != "203.0.113.7" || host != "example.com" || scheme != "https" {
+		t.Errorf("trusted proxy X-Forwarded-* headers are not applied: %s %s %s", clientIp, host, scheme)
+	}
+
+	call("192.168.1.1:5000", map[string]string{"Forwarded": "for=203.0.113.8;proto=https;host=example.org"})
+	if clientIp != "203.0.113.8" || host != "example.org" || scheme != "https" {
+		t.Errorf("trusted proxy Forwarded header is not applied: %s %s %s", clientIp, host, scheme)
+	}
+
+	call("203.0.113.9:5000", map[string]string{"X-Forwarded-For": "1.1.1.1", "X-Forwarded-Host": "evil.net"})
+	if clientIp != "203.0.113.9" || host == "evil.net" {
+		t.Errorf("untrusted client headers are applied: %s %s", clientIp, host)
+	}
+}
+
+func TestPathPrefix(t *testing.T) {
+	app := NewAppBase(&testAppSettings{})
+	app.baseSettings.BaseUrl = "https://example.com/tools/myapp/"
+	app.baseSettings.WebserverCookieSecret = "test"
+	app.baseSettings.WebApiCsrfDisabled = true
+	app.WebApiPathPrefix = "/api"
+
+	app.ApiHandler("/login", func(r *ApiRequest) error {
+		r.SessionSet("user", "alice")
+		return r.Session().Save()
+	})
+
+	app.BuildWebRouterF = func(r *gin.Engine) {
+		r.GET("/page/", func(c *gin.Context) { c.String(200, c.Request.URL.Path) })
+	}
+	app.buildWebRouter()
+
+	if app.PathPrefix() != "/tools/myapp" {
+		t.Fatalf("unexpected path prefix: %s", app.PathPrefix())
+	}
+
+	handler := app.Handler()
+
+	for path, expected := range map[string]string{
+		"/tools/myapp/page/": "/page/",
+		"/page/":             "/page/", // proxy stripped prefix already
+	} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if w.Code != 200 || w.Body.String() != expected {
+			t.Errorf("%s: unexpected reply %d %s", path, w.Code, w.Body.String())
+		}
+	}
+
+	// trailing slash redirect keeps prefix
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tools/myapp/page", nil))
+
+	if location := w.Header().Get("Location"); location != "/tools/myapp/page/" {
+		t.Errorf("unexpected redirect location: %s", location)
+	}
+
+	// session cookie path
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/tools/myapp/api/login", nil))
+
+	cookies := w.Result().Cookies()
+	if w.Code != 200 || len(cookies) == 0 || cookies[0].Path != "/tools/myapp" {
+		t.Errorf("unexpected session cookie: %d %v", w.Code, cookies)
+	}
+}
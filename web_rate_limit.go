@@ -0,0 +1,172 @@
+package goapp
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+// What rate limit buckets are keyed by
+type RateLimitKey int
+
+const (
+	RateLimitByIp      RateLimitKey = iota // client IP (see gin's ClientIP())
+	RateLimitBySession                     // session (clients can drop cookies, do not use for login handlers)
+	RateLimitByUser                        // user ID returned by AppBase.CurrentUserIdF, client IP for anonymous users
+)
+
+const rateLimitSessionKey = "goapp.rate_limit_id"
+
+// Token bucket rate limit: Requests per Period with bursts up to Burst requests.
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int // bucket size, Requests by default
+	Key      RateLimitKey
+}
+
+// Limits handler calls rate. Use it for login handlers to prevent password brute-forcing:
+//
+//	app.ApiHandler("/login", loginHandler, goapp.ApiRateLimit(goapp.RateLimit{Requests: 5, Period: time.Minute}))
+func ApiRateLimit(limit RateLimit) ApiHandlerOption {
+	return func(entry *apiHandlerEntry) {
+		entry.rateLimiter = newRateLimiter(limit)
+	}
+}
+
+// In-memory token bucket limiter.
+type rateLimiter struct {
+	limit RateLimit
+	rate  float64 // tokens per second
+
+	mutex       sync.Mutex
+	buckets     map[string]*rateBucket
+	lastEvictAt time.Time
+}
+
+type rateBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+func newRateLimiter(limit RateLimit) *rateLimiter {
+	if limit.Requests <= 0 || limit.Period <= 0 {
+		log.Panicf("rate limit requests and period should be positive: %+v", limit)
+	}
+
+	if limit.Burst <= 0 {
+		limit.Burst = limit.Requests
+	}
+
+	return &rateLimiter{
+		limit:   limit,
+		rate:    float64(limit.Requests) / limit.Period.Seconds(),
+		buckets: make(map[string]*rateBucket),
+	}
+}
+
+// Takes token from key's bucket. Returns false if bucket is empty and time to wait for next token.
+func (l *rateLimiter) allow(key string, now time.Time) (ok bool, remaining int, retryAfter time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.evictIdle(now)
+
+	bucket, exists := l.buckets[key]
+	if !exists {
+		bucket = &rateBucket{tokens: float64(l.limit.Burst), updatedAt: now}
+		l.buckets[key] = bucket
+	}
+
+	// refill
+	bucket.tokens = math.Min(float64(l.limit.Burst), bucket.tokens+now.Sub(bucket.updatedAt).Seconds()*l.rate)
+	bucket.updatedAt = now
+
+	if bucket.tokens < 1 {
+		return false, 0, time.Duration((1 - bucket.tokens) / l.rate * float64(time.Second))
+	}
+
+	bucket.tokens--
+
+	return true, int(bucket.tokens), 0
+}
+
+// Time for bucket with given tokens count to become full.
+func (l *rateLimiter) fullAfter(tokens float64) time.Duration {
+	return time.Duration((float64(l.limit.Burst) - tokens) / l.rate * float64(time.Second))
+}
+
+// Removes buckets that are full again (same as new ones). Called once per period.
+func (l *rateLimiter) evictIdle(now time.Time) {
+	if now.Sub(l.lastEvictAt) < l.limit.Period {
+		return
+	}
+
+	l.lastEvictAt = now
+
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.updatedAt) >= l.fullAfter(bucket.tokens) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Returns bucket key for request.
+func (app *AppBase) rateLimitKey(c *gin.Context, key RateLimitKey) string {
+	switch key {
+	case RateLimitBySession:
+		session := sessions.Default(c)
+
+		id, _ := session.Get(rateLimitSessionKey).(string)
+		if id == "" {
+			idBytes := make([]byte, 16)
+			rand.Read(idBytes)
+			id = base64.RawURLEncoding.EncodeToString(idBytes)
+
+			session.Set(rateLimitSessionKey, id)
+			session.Save()
+		}
+
+		return "session:" + id
+	case RateLimitByUser:
+		if userId := app.currentUserId(c); userId != "" {
+			return "user:" + userId
+		}
+	}
+
+	return "ip:" + c.ClientIP()
+}
+
+// Checks request against limiter. Sets X-RateLimit-* headers and aborts request with 429 status if
+// limit is exceeded. Returns false if request was aborted.
+func (app *AppBase) checkRateLimit(c *gin.Context, l *rateLimiter) bool {
+	ok, remaining, retryAfter := l.allow(app.rateLimitKey(c, l.limit.Key), time.Now())
+
+	c.Header("X-RateLimit-Limit", strconv.Itoa(l.limit.Burst))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+
+	if ok {
+		return true
+	}
+
+	retrySeconds := strconv.Itoa(int(math.Ceil(retryAfter.Seconds())))
+
+	c.Header("X-RateLimit-Reset", retrySeconds)
+	c.Header("Retry-After", retrySeconds)
+
+	app.logger.WarnContext(c.Request.Context(), "API Request rate limited",
+		"component", LogComponentApi, "path", c.Request.URL.Path, "client_ip", c.ClientIP(),
+	)
+
+	apiErrorReply(c, http.StatusTooManyRequests, "too many requests")
+
+	return false
+}
@@ -0,0 +1,73 @@
+package goapp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	l := newRateLimiter(RateLimit{Requests: 60, Period: time.Minute, Burst: 2})
+	now := time.Now()
+
+	for i := range 2 {
+		if ok, remaining, _ := l.allow("a", now); !ok || remaining != 1-i {
+			t.Fatalf("request %d: expected to be allowed with %d remaining", i, 1-i)
+		}
+	}
+
+	ok, _, retryAfter := l.allow("a", now)
+	if ok || retryAfter != time.Second {
+		t.Errorf("expected to be limited for 1s, got %v %v", ok, retryAfter)
+	}
+
+	if ok, _, _ := l.allow("b", now); !ok {
+		t.Error("buckets are not separated by key")
+	}
+
+	if ok, _, _ := l.allow("a", now.Add(time.Second)); !ok {
+		t.Error("bucket is not refilled")
+	}
+
+	// idle buckets are evicted
+	l.allow("c", now.Add(2*time.Minute))
+
+	if len(l.buckets) != 1 {
+		t.Errorf("expected idle buckets to be evicted, %d left", len(l.buckets))
+	}
+}
+
+func TestApiRateLimit(t *testing.T) {
+	app := NewAppBase(&testAppSettings{})
+	app.WebApiPathPrefix = "/api"
+	app.baseSettings.WebserverCookieSecret = "test"
+	app.baseSettings.WebApiCsrfDisabled = true
+
+	app.ApiHandler("/login", func(r *ApiRequest) error { return nil }, ApiRateLimit(RateLimit{Requests: 2, Period: time.Minute}))
+	app.ApiHandler("/other", func(r *ApiRequest) error { return nil })
+	app.buildWebRouter()
+
+	call := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		app.webRouter.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, nil))
+		return w
+	}
+
+	for range 2 {
+		if w := call("/api/login"); w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", w.Code)
+		}
+	}
+
+	w := call("/api/login")
+
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "30" ||
+		w.Header().Get("X-RateLimit-Limit") != "2" || w.Header().Get("X-RateLimit-Remaining") != "0" {
+		t.Errorf("unexpected limited reply: %d %v", w.Code, w.Header())
+	}
+
+	if w := call("/api/other"); w.Code != http.StatusOK {
+		t.Errorf("other handler should not be limited, got %d", w.Code)
+	}
+}
@@ -0,0 +1,94 @@
+package goapp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Header with request ID. Incoming value is used if it looks sane, new ID is generated otherwise.
+const RequestIdHeaderName = "X-Request-ID"
+
+// gin context key with request ID
+const requestIdGinKey = "goapp.request_id"
+
+type requestIdContextKey struct{}
+
+// Returns request ID for current request.
+func RequestId(c *gin.Context) string {
+	return c.GetString(requestIdGinKey)
+}
+
+// Returns request ID carried by context (request context or worker job context). Empty string if
+// there is no one.
+func RequestIdFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	id, _ := ctx.Value(requestIdContextKey{}).(string)
+	return id
+}
+
+// Returns ctx with request ID.
+func ContextWithRequestId(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIdContextKey{}, id)
+}
+
+// gin middleware accepting or generating request ID. ID is set to response header, gin context
+// and request context (so it gets to log lines logged with *Context() slog methods).
+func requestIdMiddleware(c *gin.Context) {
+	id := c.GetHeader(RequestIdHeaderName)
+
+	if !isValidRequestId(id) {
+		idBytes := make([]byte, 16)
+		rand.Read(idBytes)
+		id = hex.EncodeToString(idBytes)
+	}
+
+	c.Set(requestIdGinKey, id)
+	c.Request = c.Request.WithContext(ContextWithRequestId(c.Request.Context(), id))
+	c.Header(RequestIdHeaderName, id)
+
+	c.Next()
+}
+
+// Accepts IDs generated by proxies and clients (UUIDs, hex strings etc), rejects anything that
+// could break log lines.
+func isValidRequestId(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.' || r == ':') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// slog handler adding request_id attribute to records logged with context carrying request ID.
+type requestIdLogHandler struct {
+	slog.Handler
+}
+
+func (h *requestIdLogHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestIdFromContext(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *requestIdLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &requestIdLogHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h *requestIdLogHandler) WithGroup(name string) slog.Handler {
+	return &requestIdLogHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,77 @@
+package goapp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testRequestIdJob struct {
+	requestId string
+}
+
+func (j *testRequestIdJob) Do() {}
+
+func (j *testRequestIdJob) DoContext(ctx context.Context) {
+	j.requestId = RequestIdFromContext(ctx)
+}
+
+func TestRequestId(t *testing.T) {
+	app := NewAppBase(&testAppSettings{})
+	app.WebApiPathPrefix = "/api"
+	app.baseSettings.WebserverCookieSecret = "test"
+	app.baseSettings.WebApiCsrfDisabled = true
+
+	var logBuffer bytes.Buffer
+	app.setLogger(slog.New(slog.NewTextHandler(&logBuffer, nil)))
+
+	pool := StartWorkerPool(context.Background(), 1)
+	defer pool.Stop()
+
+	job := &testRequestIdJob{}
+
+	app.ApiHandler("/job", func(r *ApiRequest) error {
+		pool.DoSingleJobContext(r.Context(), job, true)
+		return nil
+	})
+	app.ApiHandler("/fail", func(r *ApiRequest) error { return errors.New("failed") })
+	app.buildWebRouter()
+
+	call := func(path, requestId string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, path, nil)
+		request.Header.Set(RequestIdHeaderName, requestId)
+
+		w := httptest.NewRecorder()
+		app.webRouter.ServeHTTP(w, request)
+
+		return w
+	}
+
+	w := call("/api/job", "abc-123")
+
+	var out map[string]any
+	json.Unmarshal(w.Body.Bytes(), &out)
+
+	if w.Header().Get(RequestIdHeaderName) != "abc-123" || out["request_id"] != "abc-123" || job.requestId != "abc-123" {
+		t.Errorf("request ID is not carried: header '%s', reply %v, job '%s'",
+			w.Header().Get(RequestIdHeaderName), out, job.requestId)
+	}
+
+	// invalid incoming ID is replaced, errors are logged with ID
+	w = call("/api/fail", "bad id\n")
+	requestId := w.Header().Get(RequestIdHeaderName)
+
+	if len(requestId) != 32 || !strings.Contains(w.Body.String(), requestId) {
+		t.Errorf("unexpected request ID '%s' in reply %s", requestId, w.Body.String())
+	}
+
+	if !strings.Contains(logBuffer.String(), "request_id="+requestId) {
+		t.Errorf("request ID is not logged: %s", logBuffer.String())
+	}
+}
@@ -3,9 +3,9 @@ package goapp
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-contrib/sessions/cookie"
@@ -18,35 +18,108 @@ func (app *AppBase) buildWebRouter() {
 
 	//Initialize Cookie-based session store
 	sessionStore := cookie.NewStore([]byte(app.baseSettings.WebserverCookieSecret))
+	sessionStore.Options(sessions.Options{Path: app.cookiePath(), MaxAge: 30 * 24 * 3600})
+
+	app.initCsp()
 
 	// Prepare router
 	app.webRouter = gin.New()
 
-	// Recovery middleware recovers from any panics and writes a 500 if there was one.
-	app.webRouter.Use(gin.Recovery())
+	// application logger for package-level helpers
+	app.webRouter.Use(app.loggerMiddleware)
+
+	// client IP and forwarded headers from reverse proxies
+	app.configureProxies()
+	app.webRouter.Use(app.forwardedHeadersMiddleware)
+
+	// request ID for logs and replies, first to be available everywhere
+	app.webRouter.Use(requestIdMiddleware)
+
+	// metrics, before errorsMiddleware to count panics as 500 replies
+	app.webRouter.Use(app.metricsMiddleware)
+
+	// recovers from panics and writes error page (or JSON) if there was one
+	app.webRouter.Use(app.errorsMiddleware)
+
+	// security headers
+	if !app.baseSettings.SecurityHeadersDisabled {
+		app.webRouter.Use(app.securityHeadersMiddleware)
+	}
+
+	// access log, outside of compression to log bytes actually sent
+	if app.accessLog != nil || app.accessLogToAppLog {
+		app.webRouter.Use(app.accessLogMiddleware)
+	}
+
+	// response compression
+	if !app.baseSettings.WebserverCompressionDisabled {
+		app.webRouter.Use(app.compressionMiddleware)
+	}
 
 	// use session store
 	app.webRouter.Use(sessions.Sessions(app.ExecutableName, sessionStore))
 
-	//extended logging if requested
-	if app.WebRouterLogQueries {
-		app.webRouter.Use(gin.Logger())
-		log.Println("Extended queries logging enabled.")
-	}
+	// maintenance mode, after sessions to let admins in
+	app.maintenanceAllowNets, _ = parseIpNets(app.baseSettings.MaintenanceAllowIps) // validated by loadSettings()
+	app.webRouter.Use(app.maintenanceMiddleware)
 
 	//API routes
 	if app.WebApiPathPrefix != "" {
-		app.webRouter.POST("/api/*any", (app).webApiRequestGinHandler)
+		app.addCsrfTokenApiHandler()
+
+		app.webApiRateLimiter = nil
+
+		if app.baseSettings.WebApiRateLimit > 0 {
+			app.webApiRateLimiter = newRateLimiter(RateLimit{
+				Requests: app.baseSettings.WebApiRateLimit,
+				Period:   time.Minute,
+				Burst:    app.baseSettings.WebApiRateLimitBurst,
+			})
+		}
+
+		app.webRouter.POST(app.WebApiPathPrefix+"/*any", (app).webApiRequestGinHandler)
 
 		if app.WebApiEnableGet {
-			app.webRouter.GET("/api/*any", (app).webApiRequestGinHandler)
+			app.webRouter.GET(app.WebApiPathPrefix+"/*any", (app).webApiRequestGinHandler)
+		}
+
+		if len(app.baseSettings.WebApiCorsOrigins) > 0 {
+			app.webRouter.OPTIONS(app.WebApiPathPrefix+"/*any", app.webApiPreflightGinHandler)
 		}
 	}
 
+	// modules routes
+	for _, m := range app.modules {
+		m.BuildWebRouter(app.webRouter)
+	}
+
 	// user provided routes
 	if app.BuildWebRouterF != nil {
 		app.BuildWebRouterF(app.webRouter)
 	}
+
+	// health endpoints (if application does not have own ones)
+	app.addHealthRoute(app.HealthPath, true)
+	app.addHealthRoute(app.ReadinessPath, false)
+
+	// metrics endpoint (if protected by token)
+	app.addMetricsRoute()
+
+	// admin listener router
+	app.buildAdminRouter()
+
+	// admin API
+	app.addMaintenanceRoutes()
+
+	// pprof and runtime stats
+	app.addDebugEndpoints()
+
+	// static files are served for paths without registered routes only, error page otherwise
+	if len(app.staticMounts) > 0 {
+		app.webRouter.NoRoute(app.staticGinHandler, app.notFoundGinHandler)
+	} else {
+		app.webRouter.NoRoute(app.notFoundGinHandler)
+	}
 }
 
 func (app *AppBase) webApiRequestGinHandler(c *gin.Context) {
@@ -56,27 +129,61 @@ func (app *AppBase) webApiRequestGinHandler(c *gin.Context) {
 	)
 
 	path := strings.TrimPrefix(c.Request.URL.Path, app.WebApiPathPrefix)
-	api_request, err = newApiRequest(c)
+	c.Set(apiPathGinKey, path)
+	app.setCorsHeaders(c)
 
-	if err == nil {
-		if handler, ok := app.webApiHandlerList[path]; ok {
-			err = handler(api_request)
-		} else {
-			err = fmt.Errorf("path '%s' not found", path)
-		}
+	// global limit is checked for unknown paths too
+	if app.webApiRateLimiter != nil && !app.checkRateLimit(c, app.webApiRateLimiter) {
+		return
 	}
 
+	api_request, err = newApiRequest(c, app.cookiePath())
 	if err != nil {
-		log.Println("API Request error: ", err)
-		http.Error(c.Writer, err.Error(), http.StatusInternalServerError)
+		// malformed request body, client error
+		app.ErrorReply(c, http.StatusBadRequest, err)
+		return
+	}
+
+	entry, ok := app.webApiHandlerList[path]
+	if !ok {
+		app.ErrorReply(c, http.StatusNotFound, fmt.Errorf("path '%s' not found", path))
+		return
+	}
+
+	if entry.rateLimiter != nil && !app.checkRateLimit(c, entry.rateLimiter) {
+		return
+	}
+
+	if !app.baseSettings.WebApiCsrfDisabled {
+		// token for next requests
+		c.Header(CsrfHeaderName, CsrfToken(c))
+
+		if !entry.csrfExempt {
+			if csrfErr := app.checkCsrf(c); csrfErr != nil {
+				app.logger.WarnContext(c.Request.Context(), "API Request rejected",
+					"component", LogComponentApi, "path", path, "client_ip", c.ClientIP(), "error", csrfErr,
+				)
+				apiErrorReply(c, http.StatusForbidden, csrfErr.Error())
+				return
+			}
+		}
+	}
+
+	if err = entry.handler(api_request); err != nil {
+		// logged and reported, message is shown in DEV mode only
+		app.ErrorReply(c, http.StatusInternalServerError, err)
 		return
 	}
 
 	// do not leave status unset
 	if api_request.GetOutData("status") == "" {
 		api_request.SetOkStatus(api_request.GetOutData("message"))
+	} else if api_request.GetOutData("status") == "error" {
+		c.Set(apiErrorGinKey, true)
 	}
 
+	api_request.SetOutData("request_id", api_request.RequestId())
+
 	//prepare reply
 	c.Writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
 	json.NewEncoder(c.Writer).Encode(api_request.outData)
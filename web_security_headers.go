@@ -0,0 +1,172 @@
+package goapp
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CSP source placeholder replaced with per-request nonce ('nonce-...')
+const CspNonceSource = "'nonce'"
+
+const cspNonceContextKey = "goapp.csp_nonce"
+
+// Content-Security-Policy builder. Directives are rendered in order they were added.
+type CspPolicy struct {
+	directives map[string][]string
+	order      []string
+}
+
+func NewCspPolicy() *CspPolicy {
+	return &CspPolicy{directives: make(map[string][]string)}
+}
+
+// Strict policy for production: only own origin allowed, inline scripts and styles need nonce.
+func NewStrictCspPolicy() *CspPolicy {
+	return NewCspPolicy().
+		Set("default-src", "'self'").
+		Set("script-src", "'self'", CspNonceSource).
+		Set("style-src", "'self'", CspNonceSource).
+		Set("img-src", "'self'", "data:").
+		Set("font-src", "'self'").
+		Set("connect-src", "'self'").
+		Set("object-src", "'none'").
+		Set("base-uri", "'self'").
+		Set("form-action", "'self'").
+		Set("frame-ancestors", "'none'")
+}
+
+// Relaxed policy for DEV mode: allows eval and websockets used by frontend dev servers.
+func NewDevCspPolicy() *CspPolicy {
+	return NewStrictCspPolicy().
+		Add("script-src", "'unsafe-eval'").
+		Add("connect-src", "ws:", "wss:").
+		Set("frame-ancestors", "'self'")
+}
+
+// Replaces directive sources.
+func (p *CspPolicy) Set(directive string, sources ...string) *CspPolicy {
+	if _, exists := p.directives[directive]; !exists {
+		p.order = append(p.order, directive)
+	}
+
+	p.directives[directive] = slices.Clone(sources)
+
+	return p //for method chaining
+}
+
+// Adds sources to directive.
+func (p *CspPolicy) Add(directive string, sources ...string) *CspPolicy {
+	for _, source := range sources {
+		if !slices.Contains(p.directives[directive], source) {
+			p.Set(directive, append(p.directives[directive], source)...)
+		}
+	}
+
+	return p //for method chaining
+}
+
+// Removes directive.
+func (p *CspPolicy) Remove(directive string) *CspPolicy {
+	delete(p.directives, directive)
+	p.order = slices.DeleteFunc(p.order, func(d string) bool { return d == directive })
+
+	return p //for method chaining
+}
+
+// Renders header value with nonce placeholders replaced.
+func (p *CspPolicy) String(nonce string) string {
+	parts := make([]string, 0, len(p.order))
+
+	for _, directive := range p.order {
+		sources := make([]string, 0, len(p.directives[directive]))
+
+		for _, source := range p.directives[directive] {
+			if source == CspNonceSource {
+				if nonce == "" {
+					continue
+				}
+
+				source = "'nonce-" + nonce + "'"
+			}
+
+			sources = append(sources, source)
+		}
+
+		parts = append(parts, strings.TrimSpace(directive+" "+strings.Join(sources, " ")))
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// Returns Content-Security-Policy builder to customize policy. Strict one is used by default in
+// production, relaxed one in DEV mode. Policy is created when web router is built, customize it
+// in BuildWebRouterF or PreRunF (nil before).
+func (app *AppBase) Csp() *CspPolicy {
+	return app.cspPolicy
+}
+
+// Creates default Content-Security-Policy before requests are served.
+func (app *AppBase) initCsp() {
+	if app.IsDevMode() {
+		app.cspPolicy = NewDevCspPolicy()
+	} else {
+		app.cspPolicy = NewStrictCspPolicy()
+	}
+}
+
+// Returns CSP nonce for current request. Use it in inline <script nonce="..."> tags. Available in
+// templates as {{cspNonce}}.
+func CspNonce(c *gin.Context) string {
+	return c.GetString(cspNonceContextKey)
+}
+
+// gin middleware setting security headers.
+func (app *AppBase) securityHeadersMiddleware(c *gin.Context) {
+	nonceBytes := make([]byte, 16)
+	rand.Read(nonceBytes)
+	nonce := base64.StdEncoding.EncodeToString(nonceBytes)
+	c.Set(cspNonceContextKey, nonce)
+
+	header := c.Writer.Header()
+	settings := app.baseSettings
+
+	header.Set("X-Content-Type-Options", "nosniff")
+
+	switch {
+	case settings.SecurityFrameOptions != "":
+		header.Set("X-Frame-Options", settings.SecurityFrameOptions)
+	case app.IsDevMode():
+		header.Set("X-Frame-Options", "SAMEORIGIN")
+	default:
+		header.Set("X-Frame-Options", "DENY")
+	}
+
+	if settings.SecurityReferrerPolicy != "" {
+		header.Set("Referrer-Policy", settings.SecurityReferrerPolicy)
+	}
+
+	if settings.SecurityPermissionsPolicy != "" {
+		header.Set("Permissions-Policy", settings.SecurityPermissionsPolicy)
+	}
+
+	// HSTS makes sense for https sites only, and it is not sent in DEV mode to not pin localhost to https
+	if settings.SecurityHstsMaxAge > 0 && strings.HasPrefix(settings.BaseUrl, "https://") && !app.IsDevMode() {
+		header.Set("Strict-Transport-Security", "max-age="+strconv.Itoa(settings.SecurityHstsMaxAge)+"; includeSubDomains")
+	}
+
+	if csp := app.Csp().String(nonce); csp != "" {
+		if app.IsDevMode() {
+			// DEV mode: report violations in browser console without breaking anything
+			header.Set("Content-Security-Policy-Report-Only", csp)
+		} else {
+			header.Set("Content-Security-Policy", csp)
+		}
+	}
+
+	c.Next()
+}
@@ -0,0 +1,107 @@
+package goapp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCspPolicyString(t *testing.T) {
+	policy := NewCspPolicy().
+		Set("default-src", "'self'").
+		Set("script-src", "'self'", CspNonceSource).
+		Add("script-src", "https://cdn.example.com", "'self'").
+		Set("upgrade-insecure-requests")
+
+	expected := "default-src 'self'; script-src 'self' 'nonce-abc' https://cdn.example.com; upgrade-insecure-requests"
+	if s := policy.String("abc"); s != expected {
+		t.Errorf("unexpected policy: %s", s)
+	}
+
+	policy.Remove("default-src")
+
+	if s := policy.String(""); s != "script-src 'self' https://cdn.example.com; upgrade-insecure-requests" {
+		t.Errorf("unexpected policy without nonce: %s", s)
+	}
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	app := NewAppBase(&testAppSettings{})
+	app.Version = "1.0.0" // production mode
+	app.baseSettings.BaseUrl = "https://example.com/"
+
+	var nonce string
+
+	app.BuildWebRouterF = func(r *gin.Engine) {
+		app.Csp().Add("img-src", "https://cdn.example.com")
+
+		r.GET("/", func(c *gin.Context) {
+			nonce = CspNonce(c)
+			c.String(200, "ok")
+		})
+	}
+	app.buildWebRouter()
+
+	w := httptest.NewRecorder()
+	app.webRouter.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if nonce == "" {
+		t.Fatal("no CSP nonce")
+	}
+
+	for name, value := range map[string]string{
+		"X-Content-Type-Options":    "nosniff",
+		"X-Frame-Options":           "DENY",
+		"Referrer-Policy":           "strict-origin-when-cross-origin",
+		"Strict-Transport-Security": "max-age=31536000; includeSubDomains",
+	} {
+		if w.Header().Get(name) != value {
+			t.Errorf("header %s: expected '%s', got '%s'", name, value, w.Header().Get(name))
+		}
+	}
+
+	if csp := w.Header().Get("Content-Security-Policy"); !strings.Contains(csp, "'nonce-"+nonce+"'") {
+		t.Errorf("CSP has no nonce: %s", csp)
+	}
+
+	if csp := w.Header().Get("Content-Security-Policy"); !strings.Contains(csp, "https://cdn.example.com") {
+		t.Errorf("CSP customization is lost: %s", csp)
+	}
+
+	// no HSTS for plain http
+	app.baseSettings.BaseUrl = "http://example.com/"
+
+	w = httptest.NewRecorder()
+	app.webRouter.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if w.Header().Get("Strict-Transport-Security") != "" {
+		t.Error("HSTS header sent for http base url")
+	}
+}
+
+func TestSecurityHeadersDevMode(t *testing.T) {
+	app := NewAppBase(&testAppSettings{})
+	app.buildWebRouter()
+
+	w := httptest.NewRecorder()
+	app.webRouter.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if w.Header().Get("Content-Security-Policy") != "" || w.Header().Get("Content-Security-Policy-Report-Only") == "" {
+		t.Error("CSP should be report-only in DEV mode")
+	}
+
+	if w.Header().Get("X-Frame-Options") != "SAMEORIGIN" {
+		t.Errorf("unexpected X-Frame-Options in DEV mode: %s", w.Header().Get("X-Frame-Options"))
+	}
+}
+
+// Returns nonce of first <style> or <script> element in page.
+func cspNonceFromPage(page string) string {
+	_, after, _ := strings.Cut(page, ` nonce="`)
+	nonce, _, _ := strings.Cut(after, `"`)
+
+	return nonce
+}
@@ -0,0 +1,293 @@
+package goapp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Options for AppBase.ServeStatic()
+type StaticOptions struct {
+	SpaMode   bool   // unknown page paths (no extension or Accept: text/html) return index file, paths under WebApiPathPrefix are never shadowed
+	IndexFile string // "index.html" by default
+}
+
+// Static files mount
+type staticMount struct {
+	prefix  string
+	fsys    fs.FS
+	options StaticOptions
+
+	etagCache sync.Map // "name|mod time|size" => etag
+}
+
+// content hash segment in file name: hex (webpack etc) or base64url (vite, esbuild)
+var (
+	staticHexHashRe       = regexp.MustCompile(`^[0-9a-f]{8,}$`)
+	staticBase64UrlHashRe = regexp.MustCompile(`^[A-Za-z0-9_]{8,}$`)
+)
+
+// Serves static files from fsys (embed.FS or os.DirFS(dir)) under URL prefix. Options can be nil.
+//
+// Files are served with ETag and Last-Modified headers (when modification time is known),
+// hashed filenames get long cache headers. Precompressed ".br" and ".gz" variants are served
+// when client accepts them. Static files never shadow routes registered in router.
+func (app *AppBase) ServeStatic(prefix string, fsys fs.FS, options *StaticOptions) *AppBase {
+	mount := &staticMount{
+		prefix: "/" + strings.Trim(prefix, "/"),
+		fsys:   fsys,
+	}
+
+	if options != nil {
+		mount.options = *options
+	}
+
+	if mount.options.IndexFile == "" {
+		mount.options.IndexFile = "index.html"
+	}
+
+	app.staticMounts = append(app.staticMounts, mount)
+
+	return app //for method chaining
+}
+
+// Router NoRoute handler serving static files.
+func (app *AppBase) staticGinHandler(c *gin.Context) {
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		return
+	}
+
+	urlPath := c.Request.URL.Path
+
+	// API paths are never served as static
+	if app.WebApiPathPrefix != "" &&
+		(urlPath == app.WebApiPathPrefix || strings.HasPrefix(urlPath, app.WebApiPathPrefix+"/")) {
+		return
+	}
+
+	var best *staticMount
+
+	for _, mount := range app.staticMounts {
+		if mount.matches(urlPath) && (best == nil || len(mount.prefix) > len(best.prefix)) {
+			best = mount
+		}
+	}
+
+	if best == nil {
+		return
+	}
+
+	name := strings.Trim(strings.TrimPrefix(urlPath, best.prefix), "/")
+
+	if best.serve(c, name) {
+		c.Abort()
+		return
+	}
+
+	// missing assets (app.js, logo.png) are 404, only page paths get index file
+	if best.options.SpaMode && isSpaPageRequest(c, name) && best.serve(c, best.options.IndexFile) {
+		c.Abort()
+	}
+}
+
+// Tells if request is for SPA page: path without extension or browser navigation.
+func isSpaPageRequest(c *gin.Context, name string) bool {
+	return path.Ext(name) == "" || strings.Contains(c.GetHeader("Accept"), "text/html")
+}
+
+func (mount *staticMount) matches(urlPath string) bool {
+	return mount.prefix == "/" || urlPath == mount.prefix || strings.HasPrefix(urlPath, mount.prefix+"/")
+}
+
+// Serves file by name. Returns false if there is no such file.
+func (mount *staticMount) serve(c *gin.Context, name string) bool {
+	name = path.Clean("/" + name)[1:] // no ".." allowed
+
+	if name == "" {
+		name = mount.options.IndexFile
+	}
+
+	info, err := fs.Stat(mount.fsys, name)
+	if err != nil {
+		return false
+	}
+
+	if info.IsDir() {
+		name = path.Join(name, mount.options.IndexFile)
+
+		if info, err = fs.Stat(mount.fsys, name); err != nil || info.IsDir() {
+			return false
+		}
+	}
+
+	header := c.Writer.Header()
+
+	if contentType := mime.TypeByExtension(path.Ext(name)); contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+
+	if isHashedFileName(path.Base(name)) {
+		header.Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		header.Set("Cache-Control", "no-cache") // revalidate using ETag
+	}
+
+	header.Add("Vary", "Accept-Encoding")
+
+	// precompressed variants
+	servedName := name
+	acceptEncoding := c.GetHeader("Accept-Encoding")
+
+	for _, variant := range []struct{ encoding, ext string }{{"br", ".br"}, {"gzip", ".gz"}} {
+		if !acceptsEncoding(acceptEncoding, variant.encoding) {
+			continue
+		}
+
+		if variantInfo, err := fs.Stat(mount.fsys, name+variant.ext); err == nil && !variantInfo.IsDir() {
+			servedName = name + variant.ext
+			info = variantInfo
+			header.Set("Content-Encoding", variant.encoding)
+			break
+		}
+	}
+
+	etagKey := servedName + "|" + info.ModTime().String() + "|" + strconv.FormatInt(info.Size(), 10)
+
+	// revalidation with known ETag: reply without opening file
+	if cached, ok := mount.etagCache.Load(etagKey); ok {
+		header.Set("ETag", cached.(string))
+
+		if etagMatches(c.GetHeader("If-None-Match"), cached.(string)) {
+			header.Del("Content-Type")
+			header.Del("Content-Encoding")
+			c.Writer.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+
+	f, err := mount.fsys.Open(servedName)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return false
+		}
+
+		content = bytes.NewReader(data)
+	}
+
+	if header.Get("ETag") == "" {
+		etag, err := fileEtag(content)
+		if err != nil {
+			return false
+		}
+
+		mount.etagCache.Store(etagKey, etag)
+		header.Set("ETag", etag)
+	}
+
+	// embed.FS has no modification times, http.ServeContent skips Last-Modified for zero time
+	http.ServeContent(c.Writer, c.Request, name, modTimeOrZero(info), content)
+
+	return true
+}
+
+// Calculates ETag from file content and rewinds it.
+func fileEtag(content io.ReadSeeker) (string, error) {
+	hash := sha256.New()
+
+	if _, err := io.Copy(hash, content); err != nil {
+		return "", err
+	}
+
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	return `"` + hex.EncodeToString(hash.Sum(nil)[:8]) + `"`, nil
+}
+
+// Checks If-None-Match header value against etag (weak comparison).
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Checks if filename contains content hash like "app.3f2a9c1b.js" or "index-B4x9kZ2q.css".
+// Such files are cached forever.
+func isHashedFileName(name string) bool {
+	name = strings.TrimSuffix(name, path.Ext(name))
+
+	for _, segment := range strings.FieldsFunc(name, func(r rune) bool { return r == '.' || r == '-' }) {
+		if isHashSegment(segment) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Hash segment looks random: hex with letters and digits or base64url with upper and lower case
+// letters and digits. Names like "my_module_v2" or "20240101" are not hashes.
+func isHashSegment(segment string) bool {
+	if !strings.ContainsAny(segment, "0123456789") {
+		return false
+	}
+
+	if staticHexHashRe.MatchString(segment) {
+		return strings.ContainsAny(segment, "abcdef")
+	}
+
+	return staticBase64UrlHashRe.MatchString(segment) &&
+		strings.ContainsAny(segment, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") &&
+		strings.ContainsAny(segment, "abcdefghijklmnopqrstuvwxyz")
+}
+
+func modTimeOrZero(info fs.FileInfo) time.Time {
+	if t := info.ModTime(); t.Unix() > 0 {
+		return t
+	}
+
+	return time.Time{}
+}
+
+// Checks if Accept-Encoding header value allows encoding (q=0 means not acceptable).
+func acceptsEncoding(acceptEncoding, encoding string) bool {
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+
+		if !strings.EqualFold(strings.TrimSpace(name), encoding) {
+			continue
+		}
+
+		params = strings.ReplaceAll(params, " ", "")
+
+		return params != "q=0" && params != "q=0.0" && params != "q=0.00" && params != "q=0.000"
+	}
+
+	return false
+}
@@ -0,0 +1,138 @@
+package goapp
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestServeStatic(t *testing.T) {
+	app := NewAppBase(&testAppSettings{})
+	app.WebApiPathPrefix = "/api"
+	app.ServeStatic("/", fstest.MapFS{
+		"index.html":                {Data: []byte("<html>index</html>")},
+		"assets/app-3f2a9c1b.js":    {Data: []byte("console.log(1)")},
+		"assets/app-3f2a9c1b.js.gz": {Data: []byte("GZIPPED")},
+	}, &StaticOptions{SpaMode: true})
+	app.BuildWebRouterF = func(r *gin.Engine) {
+		r.GET("/hello", func(c *gin.Context) { c.String(200, "hello") })
+	}
+	app.buildWebRouter()
+
+	request := func(path string, headers ...string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		for i := 0; i+1 < len(headers); i += 2 {
+			r.Header.Set(headers[i], headers[i+1])
+		}
+
+		w := httptest.NewRecorder()
+		app.webRouter.ServeHTTP(w, r)
+
+		return w
+	}
+
+	if w := request("/hello"); w.Body.String() != "hello" {
+		t.Errorf("route shadowed by static files: %s", w.Body.String())
+	}
+
+	w := request("/assets/app-3f2a9c1b.js")
+	if w.Code != 200 || w.Header().Get("Cache-Control") != "public, max-age=31536000, immutable" {
+		t.Errorf("unexpected hashed file reply: %d %v", w.Code, w.Header())
+	}
+
+	if w := request("/assets/app-3f2a9c1b.js", "If-None-Match", w.Header().Get("ETag")); w.Code != http.StatusNotModified {
+		t.Errorf("expected 304 for matching ETag, got %d", w.Code)
+	}
+
+	w = request("/assets/app-3f2a9c1b.js", "Accept-Encoding", "gzip, br;q=0")
+	if w.Body.String() != "GZIPPED" || w.Header().Get("Content-Encoding") != "gzip" ||
+		w.Header().Get("Content-Type") != "text/javascript; charset=utf-8" {
+		t.Errorf("precompressed variant not served: %s %v", w.Body.String(), w.Header())
+	}
+
+	if w := request("/some/spa/route"); w.Body.String() != "<html>index</html>" || w.Header().Get("Cache-Control") != "no-cache" {
+		t.Errorf("unexpected SPA fallback reply: %s %v", w.Body.String(), w.Header())
+	}
+
+	// missing assets are not replaced with index page
+	if w := request("/assets/missing-3f2a9c1b.js", "Accept", "*/*"); w.Code != http.StatusNotFound {
+		t.Errorf("missing asset: expected 404, got %d", w.Code)
+	}
+
+	// browser navigation to path with dot still gets index page
+	if w := request("/users/john.doe", "Accept", "text/html,application/xhtml+xml"); w.Body.String() != "<html>index</html>" {
+		t.Errorf("unexpected SPA fallback reply for page with dot: %d %s", w.Code, w.Body.String())
+	}
+
+	if w := request("/api/unknown"); w.Code != http.StatusNotFound {
+		t.Errorf("API path shadowed by SPA fallback: %d", w.Code)
+	}
+}
+
+func TestIsHashedFileName(t *testing.T) {
+	for name, expected := range map[string]bool{
+		"app.3f2a9c1b.js":           true,
+		"index-B4x9kZ2q.css":        true,
+		"main.js":                   false,
+		"jquery-datatables.js":      false,
+		"bootstrap.bundle.min.js":   false,
+		"chunk.8f14e45fceea167a.js": true,
+		"my_module_v2.js":           false,
+		"report-20240101.pdf":       false,
+		"app_3f2a9c1b.js":           false,
+	} {
+		if isHashedFileName(name) != expected {
+			t.Errorf("%s: expected %v", name, expected)
+		}
+	}
+}
+
+// counts opened files
+type openCountingFS struct {
+	fstest.MapFS
+	opened int
+}
+
+func (cfs *openCountingFS) Open(name string) (fs.File, error) {
+	cfs.opened++
+	return cfs.MapFS.Open(name)
+}
+
+func TestServeStaticNotModified(t *testing.T) {
+	fsys := &openCountingFS{MapFS: fstest.MapFS{"app.js": {Data: []byte("console.log(1)")}}}
+
+	app := NewAppBase(&testAppSettings{})
+	app.ServeStatic("/", fsys, nil)
+	app.buildWebRouter()
+
+	request := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/app.js", nil)
+		if ifNoneMatch != "" {
+			r.Header.Set("If-None-Match", ifNoneMatch)
+		}
+
+		w := httptest.NewRecorder()
+		app.webRouter.ServeHTTP(w, r)
+
+		return w
+	}
+
+	etag := request("").Header().Get("ETag")
+	opened := fsys.opened
+
+	if w := request("W/" + etag); w.Code != http.StatusNotModified || w.Header().Get("ETag") != etag {
+		t.Errorf("expected 304 with ETag, got %d %v", w.Code, w.Header())
+	}
+
+	if fsys.opened != opened {
+		t.Error("file should not be opened for 304 reply")
+	}
+
+	if w := request(`"other"`); w.Code != 200 || w.Body.String() != "console.log(1)" {
+		t.Errorf("unexpected reply for changed ETag: %d %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,314 @@
+package goapp
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+// Options for AppBase.UseTemplates()
+type TemplateOptions struct {
+	LayoutsDir    string           // layout templates directory, "layouts" by default
+	PartialsDir   string           // partial templates directory, "partials" by default
+	PagesDir      string           // page templates directory, "pages" by default
+	Extension     string           // templates files extension, ".html" by default
+	DefaultLayout string           // layout name used by Render(), "base" by default
+	NoLayout      bool             // render pages without layout
+	DevDir        string           // directory on disk with same templates to reload them from on every request in DEV mode (required for reloading)
+	FuncMap       template.FuncMap // additional template functions
+}
+
+// Parsed templates set.
+//
+// Every page file from PagesDir is parsed together with all layouts and partials. Page file body
+// becomes "content" template (and can define other blocks like "title"), layouts include it with
+// {{template "content" .}}. Page name is its path relative to PagesDir without extension.
+type templateEngine struct {
+	fsys    fs.FS
+	options TemplateOptions
+
+	mutex sync.RWMutex
+	pages map[string]*template.Template // page name => templates set
+}
+
+// Data passed to templates by Render(): {{.Data}} is handler provided data.
+type TemplateData struct {
+	Data any
+	Page string
+}
+
+// Loads html/template files from fsys (usually embed.FS). Options can be nil. Set
+// TemplateOptions.DevDir to reload templates on every request in DEV mode.
+func (app *AppBase) UseTemplates(fsys fs.FS, options *TemplateOptions) *AppBase {
+	engine := &templateEngine{fsys: fsys}
+
+	if options != nil {
+		engine.options = *options
+	}
+
+	if engine.options.DefaultLayout == "" {
+		engine.options.DefaultLayout = "base"
+	}
+
+	if engine.options.LayoutsDir == "" {
+		engine.options.LayoutsDir = "layouts"
+	}
+
+	if engine.options.PartialsDir == "" {
+		engine.options.PartialsDir = "partials"
+	}
+
+	if engine.options.PagesDir == "" {
+		engine.options.PagesDir = "pages"
+	}
+
+	if engine.options.Extension == "" {
+		engine.options.Extension = ".html"
+	}
+
+	app.templates = engine
+
+	return app //for method chaining
+}
+
+// Renders page with default layout and writes it with 200 status.
+func (app *AppBase) Render(c *gin.Context, page string, data any) {
+	app.RenderStatus(c, http.StatusOK, page, data)
+}
+
+// Renders page with default layout and writes it with given status.
+func (app *AppBase) RenderStatus(c *gin.Context, status int, page string, data any) {
+	html, err := app.RenderToString(c, page, data)
+
+	if err != nil {
+		app.componentLogger(LogComponentWeb).ErrorContext(c.Request.Context(), "Template rendering failed", "page", page, "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.Data(status, "text/html; charset=utf-8", []byte(html))
+}
+
+// Renders page with default layout to string.
+func (app *AppBase) RenderToString(c *gin.Context, page string, data any) (string, error) {
+	if app.templates == nil {
+		return "", fmt.Errorf("templates are not configured, call UseTemplates() first")
+	}
+
+	if app.IsDevMode() && app.templates.options.DevDir != "" {
+		// reload from disk on every request in DEV mode
+		if err := app.templates.load(os.DirFS(app.templates.options.DevDir), app.templateFuncs()); err != nil {
+			return "", err
+		}
+	}
+
+	t, err := app.templates.page(page)
+	if err != nil {
+		return "", err
+	}
+
+	//request bound functions
+	if t, err = t.Clone(); err != nil {
+		return "", err
+	}
+
+	t.Funcs(app.templateRequestFuncs(c))
+
+	name := app.templates.options.DefaultLayout
+	if app.templates.options.NoLayout {
+		name = "content"
+	}
+
+	var buffer bytes.Buffer
+
+	if err := t.ExecuteTemplate(&buffer, name, &TemplateData{Data: data, Page: page}); err != nil {
+		return "", err
+	}
+
+	return buffer.String(), nil
+}
+
+// Parses templates on startup (to report errors early).
+func (app *AppBase) loadTemplates() error {
+	if app.templates == nil {
+		return nil
+	}
+
+	return app.templates.load(app.templates.fsys, app.templateFuncs())
+}
+
+// Adds template function bound to request. f is called for every rendering and should return
+// function value to be used in templates.
+func (app *AppBase) AddTemplateRequestFunc(name string, f func(c *gin.Context) any) *AppBase {
+	if app.templateRequestFuncList == nil {
+		app.templateRequestFuncList = make(map[string]func(c *gin.Context) any)
+	}
+
+	app.templateRequestFuncList[name] = f
+
+	return app //for method chaining
+}
+
+// Functions available in all templates.
+func (app *AppBase) templateFuncs() template.FuncMap {
+	baseUrl := func() string { return strings.TrimSuffix(app.baseSettings.BaseUrl, "/") }
+
+	funcs := template.FuncMap{
+		// full URL for path
+		"url": func(p string) string { return baseUrl() + "/" + strings.TrimPrefix(p, "/") },
+		// URL for static asset with version to invalidate caches
+		"asset": func(p string) string {
+			return baseUrl() + "/" + strings.TrimPrefix(p, "/") + "?v=" + app.Version + "-" + app.BuildCommit
+		},
+		"appName":    func() string { return app.AppName },
+		"appVersion": func() string { return app.Version },
+		"isDevMode":  app.IsDevMode,
+	}
+
+	//placeholders for request bound functions, real ones are set for every rendering
+	for name := range app.templateRequestFuncsWithBuiltIn() {
+		funcs[name] = func(...any) any { return nil }
+	}
+
+	for name, f := range app.templates.options.FuncMap {
+		funcs[name] = f
+	}
+
+	return funcs
+}
+
+func (app *AppBase) templateRequestFuncs(c *gin.Context) template.FuncMap {
+	funcs := template.FuncMap{}
+
+	for name, f := range app.templateRequestFuncsWithBuiltIn() {
+		funcs[name] = f(c)
+	}
+
+	return funcs
+}
+
+func (app *AppBase) templateRequestFuncsWithBuiltIn() map[string]func(c *gin.Context) any {
+	list := map[string]func(c *gin.Context) any{
+		// session value
+		"session": func(c *gin.Context) any {
+			return func(key string) any { return sessions.Default(c).Get(key) }
+		},
+		// current request path
+		"currentPath": func(c *gin.Context) any {
+			return func() string { return c.Request.URL.Path }
+		},
+		// CSP nonce for inline scripts and styles
+		"cspNonce": func(c *gin.Context) any {
+			return func() string { return CspNonce(c) }
+		},
+		// CSRF token for web API calls
+		"csrfToken": func(c *gin.Context) any {
+			return func() string { return CsrfToken(c) }
+		},
+	}
+
+	for name, f := range app.templateRequestFuncList {
+		list[name] = f
+	}
+
+	return list
+}
+
+// Parses all pages with layouts and partials from fsys.
+func (engine *templateEngine) load(fsys fs.FS, funcs template.FuncMap) error {
+	common, err := engine.parseDir(template.New("").Funcs(funcs), fsys, engine.options.LayoutsDir)
+	if err != nil {
+		return err
+	}
+
+	if common, err = engine.parseDir(common, fsys, engine.options.PartialsDir); err != nil {
+		return err
+	}
+
+	pages := make(map[string]*template.Template)
+
+	err = fs.WalkDir(fsys, engine.options.PagesDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(p, engine.options.Extension) {
+			return err
+		}
+
+		source, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+
+		t, err := common.Clone()
+		if err != nil {
+			return err
+		}
+
+		if _, err := t.New("content").Parse(string(source)); err != nil {
+			return fmt.Errorf("template %s: %w", p, err)
+		}
+
+		name := strings.TrimSuffix(strings.TrimPrefix(p, engine.options.PagesDir+"/"), engine.options.Extension)
+		pages[name] = t
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	engine.mutex.Lock()
+	engine.pages = pages
+	engine.mutex.Unlock()
+
+	return nil
+}
+
+// Parses all templates from dir (if it exists) into t.
+func (engine *templateEngine) parseDir(t *template.Template, fsys fs.FS, dir string) (*template.Template, error) {
+	if _, err := fs.Stat(fsys, dir); err != nil {
+		return t, nil // no such directory
+	}
+
+	err := fs.WalkDir(fsys, dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(p, engine.options.Extension) {
+			return err
+		}
+
+		source, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+
+		// template name is file name without extension: layouts/base.html => "base"
+		name := strings.TrimSuffix(path.Base(p), engine.options.Extension)
+
+		if _, err := t.New(name).Parse(string(source)); err != nil {
+			return fmt.Errorf("template %s: %w", p, err)
+		}
+
+		return nil
+	})
+
+	return t, err
+}
+
+func (engine *templateEngine) page(name string) (*template.Template, error) {
+	engine.mutex.RLock()
+	defer engine.mutex.RUnlock()
+
+	t, ok := engine.pages[name]
+	if !ok {
+		return nil, fmt.Errorf("page template '%s' not found", name)
+	}
+
+	return t, nil
+}
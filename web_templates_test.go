@@ -0,0 +1,40 @@
+package goapp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestTemplates(t *testing.T) {
+	app := NewAppBase(&testAppSettings{})
+	app.baseSettings.BaseUrl = "https://example.com/"
+	app.UseTemplates(fstest.MapFS{
+		"layouts/base.html":      {Data: []byte(`<title>{{template "title" .}}</title>{{template "menu" .}}{{template "content" .}}`)},
+		"partials/menu.html":     {Data: []byte(`<a href="{{url "/users"}}">{{appName}}</a>`)},
+		"pages/admin/users.html": {Data: []byte(`{{define "title"}}Users{{end}}<p>{{.Data.Count}} users at {{currentPath}}</p>`)},
+	}, nil)
+
+	app.AppName = "Test"
+	app.BuildWebRouterF = func(r *gin.Engine) {
+		r.GET("/admin/users", func(c *gin.Context) {
+			app.Render(c, "admin/users", map[string]int{"Count": 3})
+		})
+	}
+	app.buildWebRouter()
+
+	if err := app.loadTemplates(); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	app.webRouter.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/users", nil))
+
+	expected := `<title>Users</title><a href="https://example.com/users">Test</a><p>3 users at /admin/users</p>`
+	if w.Code != 200 || w.Body.String() != expected {
+		t.Errorf("unexpected rendering result: %d %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,326 @@
+package goapp
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/mitoteam/mttools"
+)
+
+// how often certificate files are checked for changes
+const tlsCertCheckInterval = 10 * time.Second
+
+// directory (next to settings file) to cache dev mode self-signed certificates in
+const devCertDirName = ".dev-tls"
+
+func (app *AppBase) IsTlsEnabled() bool {
+	return app.baseSettings.WebserverTls
+}
+
+// Builds TLS config from settings. Generates self-signed certificate in DEV mode if no
+// certificate files set.
+func (app *AppBase) buildTlsConfig() (*tls.Config, error) {
+	minVersion, err := parseTlsVersion(app.baseSettings.WebserverTlsMinVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	cipherSuites, err := parseTlsCiphers(app.baseSettings.WebserverTlsCiphers)
+	if err != nil {
+		return nil, err
+	}
+
+	certFile := app.baseSettings.WebserverTlsCertFile
+	keyFile := app.baseSettings.WebserverTlsKeyFile
+
+	if certFile == "" || keyFile == "" {
+		if !app.IsDevMode() {
+			return nil, errors.New("webserver_tls_cert_file and webserver_tls_key_file are required for TLS")
+		}
+
+		dir := filepath.Join(filepath.Dir(app.AppSettingsFilename), devCertDirName)
+
+		if certFile, keyFile, err = devSelfSignedCertificate(dir, app.baseSettings.WebserverHostname, app.componentLogger(LogComponentWeb)); err != nil {
+			return nil, err
+		}
+	}
+
+	reloader, err := newTlsCertReloader(certFile, keyFile, app.componentLogger(LogComponentWeb))
+	if err != nil {
+		return nil, err
+	}
+
+	reloader.watch(app.BaseContext, tlsCertCheckInterval)
+
+	return &tls.Config{
+		MinVersion:     minVersion,
+		CipherSuites:   cipherSuites,
+		GetCertificate: reloader.GetCertificate,
+	}, nil
+}
+
+// Builds plain HTTP server redirecting everything to BaseUrl. Returns nil if redirect is not enabled.
+func (app *AppBase) buildHttpRedirectServer() *http.Server {
+	if app.baseSettings.WebserverHttpRedirectAddress == "" {
+		return nil
+	}
+
+	// request URI has path prefix already
+	target := strings.TrimSuffix(strings.TrimSuffix(app.baseSettings.BaseUrl, "/"), app.PathPrefix())
+
+	return &http.Server{
+		Addr:              app.baseSettings.WebserverHttpRedirectAddress,
+		ReadHeaderTimeout: 10 * time.Second,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, target+r.URL.RequestURI(), http.StatusMovedPermanently)
+		}),
+	}
+}
+
+// Prints TLS settings for `info` command.
+func (app *AppBase) printTlsInfo() {
+	if !app.IsTlsEnabled() {
+		fmt.Println("TLS: disabled")
+		return
+	}
+
+	fmt.Println("TLS: enabled")
+
+	if app.baseSettings.WebserverTlsCertFile == "" {
+		fmt.Println("Certificate: self-signed (DEV mode only)")
+	} else {
+		fmt.Printf("Certificate: %s\n", app.baseSettings.WebserverTlsCertFile)
+	}
+
+	minVersion, err := parseTlsVersion(app.baseSettings.WebserverTlsMinVersion)
+	if err != nil {
+		fmt.Printf("Min version: ERROR %s\n", err)
+	} else {
+		fmt.Printf("Min version: %s\n", tls.VersionName(minVersion))
+	}
+
+	cipherSuites, err := parseTlsCiphers(app.baseSettings.WebserverTlsCiphers)
+	if err != nil {
+		fmt.Printf("Ciphers: ERROR %s\n", err)
+	} else if len(cipherSuites) == 0 {
+		fmt.Println("Ciphers: Go defaults")
+	} else {
+		names := make([]string, 0, len(cipherSuites))
+		for _, id := range cipherSuites {
+			names = append(names, tls.CipherSuiteName(id))
+		}
+
+		fmt.Printf("Ciphers: %s\n", strings.Join(names, ", "))
+	}
+
+	if app.baseSettings.WebserverHttpRedirectAddress != "" {
+		fmt.Printf("HTTP redirect: %s => %s\n", app.baseSettings.WebserverHttpRedirectAddress, app.baseSettings.BaseUrl)
+	}
+}
+
+func parseTlsVersion(version string) (uint16, error) {
+	switch version {
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2", "":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unknown TLS version '%s'", version)
+	}
+}
+
+// Converts cipher suite names (as in crypto/tls constants) to IDs. Empty list = Go defaults.
+func parseTlsCiphers(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	known := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		known[suite.Name] = suite.ID
+	}
+
+	ids := make([]uint16, 0, len(names))
+
+	for _, name := range names {
+		id, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown or insecure TLS cipher suite '%s'", name)
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// Loads certificate and reloads it when files change. Files are polled by watch() goroutine,
+// handshakes only read current certificate.
+type tlsCertReloader struct {
+	certFile string
+	keyFile  string
+	logger   *slog.Logger
+
+	cert    atomic.Pointer[tls.Certificate]
+	modTime time.Time // files modification time of loaded certificate, used by watch() goroutine only
+}
+
+func newTlsCertReloader(certFile, keyFile string, logger *slog.Logger) (*tlsCertReloader, error) {
+	r := &tlsCertReloader{certFile: certFile, keyFile: keyFile, logger: logger}
+
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *tlsCertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.cert.Load(), nil
+}
+
+// Starts goroutine checking files for changes every interval until ctx is done.
+func (r *tlsCertReloader) watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.check()
+			}
+		}
+	}()
+}
+
+// Reloads certificate if files were changed.
+func (r *tlsCertReloader) check() {
+	if !r.filesModTime().After(r.modTime) {
+		return
+	}
+
+	if err := r.load(); err != nil {
+		// keep using previous certificate
+		r.logger.Error("TLS certificate reload failed", "file", r.certFile, "error", err)
+	} else {
+		r.logger.Info("TLS certificate reloaded", "file", r.certFile)
+	}
+}
+
+func (r *tlsCertReloader) load() error {
+	modTime := r.filesModTime()
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	r.cert.Store(&cert)
+	r.modTime = modTime
+
+	return nil
+}
+
+// Returns latest modification time of certificate and key files.
+func (r *tlsCertReloader) filesModTime() (t time.Time) {
+	for _, path := range []string{r.certFile, r.keyFile} {
+		if info, err := os.Stat(path); err == nil && info.ModTime().After(t) {
+			t = info.ModTime()
+		}
+	}
+
+	return t
+}
+
+// Returns paths to cached self-signed certificate for hostname. Generates new one if there is
+// no cached certificate or it expires soon.
+func devSelfSignedCertificate(dir, hostname string, logger *slog.Logger) (certFile, keyFile string, err error) {
+	certFile = filepath.Join(dir, hostname+".crt")
+	keyFile = filepath.Join(dir, hostname+".key")
+
+	if mttools.IsFileExists(certFile) && mttools.IsFileExists(keyFile) {
+		if cert, err := tls.LoadX509KeyPair(certFile, keyFile); err == nil {
+			if leaf, err := x509.ParseCertificate(cert.Certificate[0]); err == nil &&
+				time.Until(leaf.NotAfter) > 7*24*time.Hour {
+				return certFile, keyFile, nil
+			}
+		}
+	}
+
+	logger.Info("Generating self-signed TLS certificate", "hostname", hostname, "file", certFile)
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", "", err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return "", "", err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: hostname, Organization: []string{"goapp DEV"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().AddDate(1, 0, 0),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+
+	if ip := net.ParseIP(hostname); ip != nil {
+		template.IPAddresses = []net.IP{ip}
+	} else {
+		template.DNSNames = []string{hostname}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return "", "", err
+	}
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		return "", "", err
+	}
+
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		return "", "", err
+	}
+
+	return certFile, keyFile, nil
+}
@@ -0,0 +1,109 @@
+package goapp
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestDevSelfSignedCertificate(t *testing.T) {
+	dir := t.TempDir()
+
+	certFile, keyFile, err := devSelfSignedCertificate(dir, "localhost", slog.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reloader, err := newTlsCertReloader(certFile, keyFile, slog.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, _ := reloader.GetCertificate(nil)
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := leaf.VerifyHostname("localhost"); err != nil {
+		t.Error(err)
+	}
+
+	//cached certificate should be reused
+	if certFile2, _, _ := devSelfSignedCertificate(dir, "localhost", slog.Default()); certFile2 != certFile {
+		t.Errorf("unexpected cached certificate path %s", certFile2)
+	}
+}
+
+func TestParseTlsSettings(t *testing.T) {
+	if v, err := parseTlsVersion("1.3"); err != nil || v != tls.VersionTLS13 {
+		t.Errorf("unexpected TLS version: %v %v", v, err)
+	}
+
+	if _, err := parseTlsVersion("2.0"); err == nil {
+		t.Error("error expected for unknown TLS version")
+	}
+
+	if ids, err := parseTlsCiphers([]string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"}); err != nil || len(ids) != 1 {
+		t.Errorf("unexpected ciphers: %v %v", ids, err)
+	}
+
+	if _, err := parseTlsCiphers([]string{"TLS_RSA_WITH_RC4_128_SHA"}); err == nil {
+		t.Error("error expected for insecure cipher")
+	}
+}
+
+func TestTlsCertReload(t *testing.T) {
+	dir := t.TempDir()
+
+	certFile, keyFile, err := devSelfSignedCertificate(dir, "localhost", slog.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newCertFile, newKeyFile, err := devSelfSignedCertificate(dir, "example.com", slog.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reloader, err := newTlsCertReloader(certFile, keyFile, slog.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reloader.watch(ctx, 10*time.Millisecond)
+
+	// replace files with newer ones
+	modTime := time.Now().Add(time.Minute)
+
+	for src, dst := range map[string]string{newCertFile: certFile, newKeyFile: keyFile} {
+		data, err := os.ReadFile(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(dst, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		os.Chtimes(dst, modTime, modTime)
+	}
+
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		cert, _ := reloader.GetCertificate(nil)
+
+		if leaf, err := x509.ParseCertificate(cert.Certificate[0]); err == nil && leaf.VerifyHostname("example.com") == nil {
+			return
+		}
+	}
+
+	t.Error("certificate was not reloaded")
+}
@@ -2,52 +2,134 @@ package goapp
 
 import (
 	"context"
+	"log/slog"
 	"time"
 
 	"github.com/alitto/pond"
 )
 
+// max tasks in pool queue
+const workerPoolQueueSize = 1000
+
 type WorkerPoolJob interface {
 	Do()
 }
 
+// Optional interface for jobs that should stop when pool context is canceled (on app shutdown
+// for pools started with AppBase.StartWorkerPool()). DoContext() is called instead of Do().
+type WorkerPoolContextJob interface {
+	WorkerPoolJob
+
+	DoContext(ctx context.Context)
+}
+
 type WorkerPool struct {
 	context    context.Context
 	workerPond *pond.WorkerPool
+	logger     *slog.Logger
+
+	name   string // AppBase pools only
+	onStop func()
 }
 
+// Starts worker pool logging job panics with slog.Default(). AppBase.StartWorkerPool() uses
+// application logger.
 func StartWorkerPool(ctx context.Context, workersCount int) *WorkerPool {
+	return startWorkerPool(ctx, workersCount, slog.Default())
+}
+
+func startWorkerPool(ctx context.Context, workersCount int, logger *slog.Logger) *WorkerPool {
 	workerPool := WorkerPool{
 		context: ctx,
+		logger:  logger.With("component", LogComponentWorker),
 	}
 
-	//max 2 workers, max 1000 tasks in queue
-	workerPool.workerPond = pond.New(workersCount, 1000, pond.MinWorkers(1), pond.Context(workerPool.context))
+	workerPool.workerPond = pond.New(workersCount, workerPoolQueueSize,
+		pond.MinWorkers(1),
+		pond.Context(workerPool.context),
+	)
 
 	return &workerPool
 }
 
+// Returns pool context. It is done when pool is stopping.
+func (wp *WorkerPool) Context() context.Context {
+	return wp.context
+}
+
+// Returns part of queue filled with waiting jobs (0..1).
+func (wp *WorkerPool) QueueUsage() float64 {
+	if wp.workerPond.Stopped() {
+		return 0
+	}
+
+	return float64(wp.workerPond.WaitingTasks()) / workerPoolQueueSize
+}
+
+// Pool jobs counters
+type WorkerPoolStats struct {
+	Waiting   uint64 // jobs in queue
+	Running   uint64 // jobs being done right now
+	Completed uint64 // jobs done since pool start
+	Workers   int    // running workers
+}
+
+func (wp *WorkerPool) Stats() WorkerPoolStats {
+	stats := WorkerPoolStats{
+		Waiting:   wp.workerPond.WaitingTasks(),
+		Completed: wp.workerPond.CompletedTasks(),
+		Workers:   wp.workerPond.RunningWorkers(),
+	}
+
+	// counters are read one by one, do not let them go below zero
+	if submitted := wp.workerPond.SubmittedTasks(); submitted > stats.Waiting+stats.Completed {
+		stats.Running = submitted - stats.Waiting - stats.Completed
+	}
+
+	return stats
+}
+
+// Returns pool name given by AppBase.StartNamedWorkerPool() or AppBase.StartWorkerPool().
+func (wp *WorkerPool) Name() string {
+	return wp.name
+}
+
 func (wp *WorkerPool) Stop() {
 	wp.workerPond.StopAndWaitFor(10 * time.Second)
+
+	if wp.onStop != nil {
+		wp.onStop()
+	}
 }
 
 func (wp *WorkerPool) DoSingleJob(job WorkerPoolJob, wait bool) {
+	wp.DoSingleJobContext(context.Background(), job, wait)
+}
+
+// Same as DoSingleJob() but carries request ID from ctx (usually ApiRequest.Context()) to job
+// context and log lines. ctx cancellation is not carried, jobs can outlive request.
+func (wp *WorkerPool) DoSingleJobContext(ctx context.Context, job WorkerPoolJob, wait bool) {
 	localJob := job //scoped copy of struct
 
 	if wait {
-		wp.workerPond.SubmitAndWait(localJob.Do)
+		wp.workerPond.SubmitAndWait(wp.jobFunc(ctx, localJob))
 	} else {
-		wp.workerPond.Submit(localJob.Do)
+		wp.workerPond.Submit(wp.jobFunc(ctx, localJob))
 	}
 }
 
 func (wp *WorkerPool) DoJobList(jobList []WorkerPoolJob, wait bool) {
+	wp.DoJobListContext(context.Background(), jobList, wait)
+}
+
+// Same as DoJobList() but carries request ID from ctx to jobs, see DoSingleJobContext().
+func (wp *WorkerPool) DoJobListContext(ctx context.Context, jobList []WorkerPoolJob, wait bool) {
 	group := wp.workerPond.Group()
 
 	// Submit a group of tasks
 	for _, job := range jobList {
 		localJob := job //scoped copy of struct
-		group.Submit(localJob.Do)
+		group.Submit(wp.jobFunc(ctx, localJob))
 	}
 
 	// Wait for all tasks in the group to complete
@@ -55,3 +137,27 @@ func (wp *WorkerPool) DoJobList(jobList []WorkerPoolJob, wait bool) {
 		group.Wait()
 	}
 }
+
+// Returns function to be submitted to pond for job.
+func (wp *WorkerPool) jobFunc(ctx context.Context, job WorkerPoolJob) func() {
+	jobContext := wp.context
+
+	if requestId := RequestIdFromContext(ctx); requestId != "" {
+		jobContext = ContextWithRequestId(jobContext, requestId)
+	}
+
+	return func() {
+		// every job is wrapped here, so pond panic handler is never called
+		defer func() {
+			if p := recover(); p != nil {
+				wp.logger.ErrorContext(jobContext, "Worker pool job panic", "panic", p)
+			}
+		}()
+
+		if contextJob, ok := job.(WorkerPoolContextJob); ok {
+			contextJob.DoContext(jobContext)
+		} else {
+			job.Do()
+		}
+	}
+}
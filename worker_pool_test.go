@@ -0,0 +1,63 @@
+package goapp
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testPanicJob struct{}
+
+func (j testPanicJob) Do() {
+	panic("job failed")
+}
+
+func TestWorkerPoolJobPanic(t *testing.T) {
+	var logBuffer bytes.Buffer
+
+	pool := startWorkerPool(context.Background(), 1, slog.New(&requestIdLogHandler{slog.NewTextHandler(&logBuffer, nil)}))
+
+	pool.DoSingleJobContext(ContextWithRequestId(context.Background(), "req-1"), testPanicJob{}, true)
+	pool.DoJobList([]WorkerPoolJob{testPanicJob{}}, true)
+	pool.Stop()
+
+	// panic is logged once per job, with request ID if there is one
+	if count := strings.Count(logBuffer.String(), "Worker pool job panic"); count != 2 {
+		t.Errorf("expected 2 panic log lines, got %d: %s", count, logBuffer.String())
+	}
+
+	if !strings.Contains(logBuffer.String(), "request_id=req-1") {
+		t.Errorf("no request ID in panic log: %s", logBuffer.String())
+	}
+
+	if stats := pool.Stats(); stats.Completed != 2 {
+		t.Errorf("expected 2 completed jobs, got %d", stats.Completed)
+	}
+}
+
+func TestWorkerPoolRemovedOnShutdown(t *testing.T) {
+	app := NewAppBase(&testAppSettings{})
+	app.initBaseContext()
+
+	app.StartNamedWorkerPool("mailer", 1)
+	app.cancelBase()
+
+	// removed asynchronously when BaseContext is canceled
+	deadline := time.Now().Add(time.Second)
+
+	for {
+		output, _ := app.jobsAdminCommand(context.Background(), nil)
+		if output == "No worker pools\n" {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("pool stopped by BaseContext is still listed: %s", output)
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}